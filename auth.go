@@ -0,0 +1,119 @@
+package hessian
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Credentials apply authentication to every hessian request
+type Credentials interface {
+	// Apply set authentication (header, query, signature ...) to request
+	Apply(*http.Request) error
+}
+
+// Refresher credentials which can be renewed, Proxy call Refresh after server
+// response 401 Unauthorized and retry the request once
+type Refresher interface {
+	// Refresh renew the credentials
+	Refresh() error
+}
+
+// CredentialsFunc adapter to use an ordinary function as Credentials, ex: custom signed header
+type CredentialsFunc func(*http.Request) error
+
+// Apply call f(r)
+func (f CredentialsFunc) Apply(r *http.Request) error {
+	return f(r)
+}
+
+// BasicAuth HTTP Basic authentication
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// Apply set Authorization: Basic header
+func (a *BasicAuth) Apply(r *http.Request) error {
+	r.SetBasicAuth(a.Username, a.Password)
+	return nil
+}
+
+// BearerAuth HTTP Bearer token authentication, it is safe for concurrent use and concurrent calls
+// unauthorized by the same token refresh it once
+type BearerAuth struct {
+	// Token current access token
+	Token string
+	// RefreshToken optional, get a new token after server response 401, without it the 401 is returned
+	// as error
+	RefreshToken func() (string, error)
+
+	mu sync.RWMutex
+	// refreshing held while refreshing, concurrent 401 responses refresh the token once
+	refreshing sync.Mutex
+}
+
+// Apply set Authorization: Bearer header
+func (a *BearerAuth) Apply(r *http.Request) error {
+	a.mu.RLock()
+	token := a.Token
+	a.mu.RUnlock()
+
+	if len(token) == 0 {
+		return fmt.Errorf("BearerAuth: token is empty")
+	}
+	r.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// refresherOf the Refresher of credentials c, BearerAuth without RefreshToken is not refreshable
+func refresherOf(c Credentials) (Refresher, bool) {
+	if a, ok := c.(*BearerAuth); ok && a.RefreshToken == nil {
+		return nil, false
+	}
+	r, ok := c.(Refresher)
+	return r, ok
+}
+
+// refresh refresh r after the signed request req is unauthorized. BearerAuth is not refreshed if its
+// token has changed since req was signed, another call has refreshed it.
+func refresh(r Refresher, req *http.Request) error {
+	a, ok := r.(*BearerAuth)
+	if !ok {
+		return r.Refresh()
+	}
+
+	a.refreshing.Lock()
+	defer a.refreshing.Unlock()
+	a.mu.RLock()
+	token := a.Token
+	a.mu.RUnlock()
+	if req.Header.Get("Authorization") != "Bearer "+token {
+		return nil
+	}
+	return a.refresh()
+}
+
+// Refresh get a new token by RefreshToken
+func (a *BearerAuth) Refresh() error {
+	a.refreshing.Lock()
+	defer a.refreshing.Unlock()
+	return a.refresh()
+}
+
+// refresh get a new token by RefreshToken, the caller hold refreshing
+func (a *BearerAuth) refresh() error {
+	if a.RefreshToken == nil {
+		return fmt.Errorf("BearerAuth: RefreshToken is not set")
+	}
+
+	token, err := a.RefreshToken()
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.Token = token
+	a.mu.Unlock()
+	return nil
+}
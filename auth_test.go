@@ -0,0 +1,235 @@
+package hessian
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBasicAuth_Apply(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost:8080/simple", nil)
+	a := &BasicAuth{Username: "ggwhite", Password: "secret"}
+	if err := a.Apply(req); err != nil {
+		t.Errorf("BasicAuth.Apply() error = %v", err)
+	}
+	u, p, ok := req.BasicAuth()
+	if !ok || u != "ggwhite" || p != "secret" {
+		t.Errorf("BasicAuth.Apply() = %v, %v, %v", u, p, ok)
+	}
+}
+
+func TestBearerAuth_Apply(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "Happy Pass",
+			token:   "abc",
+			want:    "Bearer abc",
+			wantErr: false,
+		},
+		{
+			name:    "Empty Token",
+			token:   "",
+			want:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, "http://localhost:8080/simple", nil)
+			a := &BearerAuth{Token: tt.token}
+			if err := a.Apply(req); (err != nil) != tt.wantErr {
+				t.Errorf("BearerAuth.Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := req.Header.Get("Authorization"); got != tt.want {
+				t.Errorf("BearerAuth.Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBearerAuth_Refresh(t *testing.T) {
+	tests := []struct {
+		name    string
+		refresh func() (string, error)
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "Happy Pass",
+			refresh: func() (string, error) { return "new", nil },
+			want:    "new",
+			wantErr: false,
+		},
+		{
+			name:    "Refresh Error",
+			refresh: func() (string, error) { return "", fmt.Errorf("boom") },
+			want:    "old",
+			wantErr: true,
+		},
+		{
+			name:    "Without RefreshToken",
+			refresh: nil,
+			want:    "old",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &BearerAuth{Token: "old", RefreshToken: tt.refresh}
+			if err := a.Refresh(); (err != nil) != tt.wantErr {
+				t.Errorf("BearerAuth.Refresh() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if a.Token != tt.want {
+				t.Errorf("BearerAuth.Refresh() token = %v, want %v", a.Token, tt.want)
+			}
+		})
+	}
+}
+
+func TestProxy_Invoke_Credentials(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("X-Sign") != "signed" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte{'r', 0x01, 0x00, 'S', 0x00, 0x02, 'o', 'k', 'z'})
+	}))
+	defer srv.Close()
+
+	proxy, err := NewProxy(&ProxyConfig{
+		Version: V1,
+		URL:     srv.URL,
+		Credentials: &BearerAuth{
+			Token:        "old",
+			RefreshToken: func() (string, error) { return "new", nil },
+		},
+		Header: http.Header{"X-Sign": []string{"signed"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := proxy.Invoke("str")
+	if err != nil {
+		t.Errorf("Proxy.Invoke() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, []interface{}{"ok"}) {
+		t.Errorf("Proxy.Invoke() = %v, want %v", got, []interface{}{"ok"})
+	}
+	if calls != 2 {
+		t.Errorf("Proxy.Invoke() calls = %v, want %v", calls, 2)
+	}
+}
+
+func TestProxy_Invoke_Unauthorized(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name        string
+		credentials Credentials
+		wantCalls   int
+	}{
+		{name: "BearerAuth without RefreshToken", credentials: &BearerAuth{Token: "old"}, wantCalls: 1},
+		{name: "BasicAuth", credentials: &BasicAuth{Username: "ggwhite", Password: "secret"}, wantCalls: 1},
+		{
+			name:        "Refreshed still 401",
+			credentials: &BearerAuth{Token: "old", RefreshToken: func() (string, error) { return "new", nil }},
+			wantCalls:   2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = 0
+			proxy, err := NewProxy(&ProxyConfig{Version: V1, URL: srv.URL, Credentials: tt.credentials})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := proxy.Invoke("str"); err == nil || !strings.Contains(err.Error(), "401") {
+				t.Errorf("Proxy.Invoke() error = %v, want http status 401", err)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("Proxy.Invoke() calls = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestProxy_Invoke_ConcurrentRefresh(t *testing.T) {
+	const n = 20
+	var mu sync.Mutex
+	var stale int
+	allStale := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer new" {
+			// hold the 401 until all calls are signed with the old token
+			mu.Lock()
+			if stale++; stale == n {
+				close(allStale)
+			}
+			mu.Unlock()
+			select {
+			case <-allStale:
+			case <-time.After(5 * time.Second):
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte{'r', 0x01, 0x00, 'S', 0x00, 0x02, 'o', 'k', 'z'})
+	}))
+	defer srv.Close()
+
+	var refreshes int32
+	auth := &BearerAuth{
+		Token: "old",
+		RefreshToken: func() (string, error) {
+			atomic.AddInt32(&refreshes, 1)
+			return "new", nil
+		},
+	}
+	proxy, err := NewProxy(&ProxyConfig{Version: V1, URL: srv.URL, Credentials: auth})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := proxy.Invoke("str"); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Proxy.Invoke() error = %v", err)
+	}
+	if got := atomic.LoadInt32(&refreshes); got != 1 {
+		t.Errorf("RefreshToken calls = %v, want 1", got)
+	}
+}
@@ -0,0 +1,108 @@
+package hessian
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Bind fill func fields of the struct which svc points to, each func call Proxy with the method
+// name from tag `hessian:"method"` or the field name, ex:
+//
+//	type UserService struct {
+//		GetUser func(ctx context.Context, id int64) (*User, error) `hessian:"getUser"`
+//		Ping    func() error
+//	}
+//
+// The first argument can be a context.Context, the last result must be an error,
+// and at most one result before it, which is read from the reply as InvokeInto.
+// The funcs are safe for concurrent use as Proxy.
+func Bind(p *Proxy, svc interface{}) error {
+	v := reflect.ValueOf(svc)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("Bind input is not a struct pointer")
+	}
+	v = v.Elem()
+	t := v.Type()
+
+	for i, l := 0, t.NumField(); i < l; i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Type.Kind() != reflect.Func {
+			continue
+		}
+
+		m := f.Tag.Get(tagName)
+		if len(m) == 0 {
+			m = f.Name
+		}
+
+		fn, err := bindFunc(p, m, f.Type)
+		if err != nil {
+			return fmt.Errorf("Bind %s.%s: %v", t, f.Name, err)
+		}
+		v.Field(i).Set(fn)
+	}
+
+	return nil
+}
+
+// bindFunc create a func of type ft which invoke method m
+func bindFunc(p *Proxy, m string, ft reflect.Type) (reflect.Value, error) {
+	if ft.IsVariadic() {
+		return reflect.Value{}, fmt.Errorf("variadic func is unsupported")
+	}
+	if ft.NumOut() == 0 || ft.NumOut() > 2 || ft.Out(ft.NumOut()-1) != errorType {
+		return reflect.Value{}, fmt.Errorf("func must return error or (value, error)")
+	}
+	hasCtx := ft.NumIn() > 0 && ft.In(0) == contextType
+
+	return reflect.MakeFunc(ft, func(in []reflect.Value) []reflect.Value {
+		ctx := context.Background()
+		if hasCtx {
+			if c, ok := in[0].Interface().(context.Context); ok && c != nil {
+				ctx = c
+			}
+			in = in[1:]
+		}
+
+		args := make([]interface{}, len(in))
+		for j := range in {
+			if (in[j].Kind() == reflect.Ptr || in[j].Kind() == reflect.Interface) && in[j].IsNil() {
+				continue
+			}
+			args[j] = in[j].Interface()
+		}
+
+		// the reply is read into the result as InvokeInto, ex: RawMessage and Unmarshaler
+		var v interface{}
+		var val reflect.Value
+		if ft.NumOut() == 2 {
+			val = reflect.New(ft.Out(0))
+			v = val.Interface()
+		}
+		err := p.InvokeInto(ctx, v, m, args...)
+		return bindResults(ft, val, err)
+	}), nil
+}
+
+// bindResults results of func type ft, val is the pointer of the result before error if any
+func bindResults(ft reflect.Type, val reflect.Value, err error) []reflect.Value {
+	out := make([]reflect.Value, ft.NumOut())
+	for j := range out {
+		out[j] = reflect.Zero(ft.Out(j))
+	}
+
+	if err == nil && val.IsValid() {
+		out[0] = val.Elem()
+	}
+
+	if err != nil {
+		out[len(out)-1] = reflect.ValueOf(&err).Elem()
+	}
+	return out
+}
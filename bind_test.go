@@ -0,0 +1,170 @@
+package hessian
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestBind(t *testing.T) {
+	type User struct {
+		Package `hessian:"lab.ggw.shs.service.User"`
+		Name    string `hessian:"name"`
+	}
+	type Service struct {
+		GetUser func(ctx context.Context, id int64) (*User, error) `hessian:"obj"`
+		Count   func() (int, error)                                `hessian:"integer"`
+		Names   func() ([]string, error)                           `hessian:"array"`
+		Ping    func() error
+		Fail    func(string) (string, error) `hessian:"fault"`
+		Raw     func() (RawMessage, error)   `hessian:"obj"`
+		Price   func() (money, error)        `hessian:"price"`
+		Ignored string
+	}
+
+	replies := map[string][]byte{
+		"obj":     {'r', 0x01, 0x00, 'M', 't', 0x00, 0x00, 'S', 0x00, 0x04, 'n', 'a', 'm', 'e', 'S', 0x00, 0x03, 'g', 'g', 'w', 'z', 'z'},
+		"integer": {'r', 0x01, 0x00, 'I', 0x00, 0x00, 0x00, 0x0c, 'z'},
+		"array":   {'r', 0x01, 0x00, 'V', 't', 0x00, 0x00, 'l', 0x00, 0x00, 0x00, 0x01, 'S', 0x00, 0x01, 'a', 'z', 'z'},
+		"Ping":    {'r', 0x01, 0x00, 'N', 'z'},
+		"price":   {'r', 0x01, 0x00, 'S', 0x00, 0x04, '1', '.', '5', '0', 'z'},
+		"fault": {'r', 0x01, 0x00, 'f',
+			'S', 0x00, 0x04, 'c', 'o', 'd', 'e', 'S', 0x00, 0x01, 'E',
+			'S', 0x00, 0x07, 'm', 'e', 's', 's', 'a', 'g', 'e', 'S', 0x00, 0x01, 'M', 'z'},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		l := int(body[4])<<8 + int(body[5])
+		w.Write(replies[string(body[6:6+l])])
+	}))
+	defer srv.Close()
+
+	proxy, err := NewProxy(&ProxyConfig{
+		Version: V1,
+		URL:     srv.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var svc Service
+	if err := Bind(proxy, &svc); err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+	if svc.Ignored != "" {
+		t.Errorf("Bind() set non func field")
+	}
+
+	u, err := svc.GetUser(context.Background(), 1)
+	if err != nil || !reflect.DeepEqual(u, &User{Name: "ggw"}) {
+		t.Errorf("GetUser() = %v, %v", u, err)
+	}
+	n, err := svc.Count()
+	if err != nil || n != 12 {
+		t.Errorf("Count() = %v, %v", n, err)
+	}
+	names, err := svc.Names()
+	if err != nil || !reflect.DeepEqual(names, []string{"a"}) {
+		t.Errorf("Names() = %v, %v", names, err)
+	}
+	if err := svc.Ping(); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+	if _, err := svc.Fail("x"); err == nil {
+		t.Errorf("Fail() error = %v, wantErr %v", err, true)
+	}
+
+	// read as InvokeInto
+	raw, err := svc.Raw()
+	if want := RawMessage(replies["obj"][3 : len(replies["obj"])-1]); err != nil || !reflect.DeepEqual(raw, want) {
+		t.Errorf("Raw() = %q, %v, want %q", raw, err, want)
+	}
+	price, err := svc.Price()
+	if err != nil || price != 150 {
+		t.Errorf("Price() = %v, %v, want 150", price, err)
+	}
+}
+
+func TestBind_Concurrent(t *testing.T) {
+	s, err := NewServer(serverService{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	proxy, err := NewProxy(&ProxyConfig{Version: V1, URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var svc struct {
+		Add func(a, b int64) (int64, error)
+	}
+	if err := Bind(proxy, &svc); err != nil {
+		t.Fatal(err)
+	}
+
+	// bound funcs are called from many goroutines, run with -race
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for k := 0; k < 20; k++ {
+		wg.Add(1)
+		go func(k int64) {
+			defer wg.Done()
+			if got, err := svc.Add(k, 1); err != nil || got != k+1 {
+				errs <- fmt.Errorf("Add() = %v, %v, want %d", got, err, k+1)
+			}
+		}(int64(k))
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestBind_Invalid(t *testing.T) {
+	proxy, _ := NewProxy(&ProxyConfig{
+		Version: V1,
+		URL:     "http://localhost:8080/simple",
+	})
+	tests := []struct {
+		name string
+		svc  interface{}
+	}{
+		{
+			name: "Not Pointer",
+			svc:  struct{}{},
+		},
+		{
+			name: "Without Error",
+			svc: &struct {
+				Get func() string
+			}{},
+		},
+		{
+			name: "Too Many Results",
+			svc: &struct {
+				Get func() (string, string, error)
+			}{},
+		},
+		{
+			name: "Variadic",
+			svc: &struct {
+				Get func(...string) error
+			}{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Bind(proxy, tt.svc); err == nil {
+				t.Errorf("Bind() error = %v, wantErr %v", err, true)
+			}
+		})
+	}
+}
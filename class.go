@@ -0,0 +1,134 @@
+package hessian
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ClassCodec encode a go type as a java class and decode the class back, for java types which are
+// not plain objects, ex: java.math.BigDecimal
+type ClassCodec struct {
+	// Class java class name, ex: java.math.BigDecimal
+	Class string
+	// Type go type, ex: reflect.TypeOf(big.Float{})
+	Type reflect.Type
+
+	// Encode optional, return the fields of v, which is of Type, written as a map of Class.
+	// A codec without Encode is only used to decode, ex: another class decoded to the same Type.
+	Encode func(v interface{}) ([]MapEntry, error)
+	// Decode return a value of Type from a map of Class
+	Decode func(m TypedMap) (interface{}, error)
+}
+
+// ClassRegistry class codecs by java class name and go type, it is safe for concurrent use
+type ClassRegistry struct {
+	mu      sync.RWMutex
+	classes map[string]*ClassCodec
+	types   map[reflect.Type]*ClassCodec
+}
+
+// NewClassRegistry create an empty class registry
+func NewClassRegistry() *ClassRegistry {
+	return &ClassRegistry{
+		classes: make(map[string]*ClassCodec),
+		types:   make(map[reflect.Type]*ClassCodec),
+	}
+}
+
+// DefaultClassRegistry class registry of serializers and deserializers by default
+var DefaultClassRegistry = NewClassRegistry()
+
+// RegisterClass register class codec c to DefaultClassRegistry
+func RegisterClass(c ClassCodec) error {
+	return DefaultClassRegistry.Register(c)
+}
+
+// Register register class codec c, it replaces the codec of the same class, and the codec of the
+// same type if c has Encode. The type of a replaced class is not encoded by it any more.
+func (r *ClassRegistry) Register(c ClassCodec) error {
+	if len(c.Class) == 0 {
+		return fmt.Errorf("ClassCodec: Class is empty")
+	}
+	if c.Type == nil {
+		return fmt.Errorf("ClassCodec %s: Type is nil", c.Class)
+	}
+	if c.Decode == nil {
+		return fmt.Errorf("ClassCodec %s: Decode is required", c.Class)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if old, ok := r.classes[c.Class]; ok && r.types[old.Type] == old {
+		// the old type is not encoded as the replaced class any more
+		delete(r.types, old.Type)
+	}
+	r.classes[c.Class] = &c
+	if c.Encode != nil {
+		r.types[c.Type] = &c
+	}
+	return nil
+}
+
+// byClass find codec of java class name
+func (r *ClassRegistry) byClass(class string) (*ClassCodec, bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.classes[class]
+	return c, ok
+}
+
+// byType find codec of go type t
+func (r *ClassRegistry) byType(t reflect.Type) (*ClassCodec, bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.types[t]
+	return c, ok
+}
+
+// writeClass write v by its class codec if registered, the pointer of a registered type and the
+// value of a registered pointer type are written as the type. It return false if no codec is registered.
+func (o *SerializerV1) writeClass(v interface{}) (bool, error) {
+	rv := reflect.ValueOf(v)
+	c, ok := o.classes.byType(rv.Type())
+	if !ok && rv.Kind() == reflect.Ptr {
+		c, ok = o.classes.byType(rv.Type().Elem())
+		if ok && !rv.IsNil() {
+			v = rv.Elem().Interface()
+		}
+	} else if !ok {
+		if c, ok = o.classes.byType(reflect.PtrTo(rv.Type())); ok {
+			p := reflect.New(rv.Type())
+			p.Elem().Set(rv)
+			v = p.Interface()
+		}
+	}
+	if !ok {
+		return false, nil
+	}
+	if rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return true, o.WriteNull()
+	}
+
+	entries, err := c.Encode(v)
+	if err != nil {
+		return true, fmt.Errorf("encode %s: %v", c.Class, err)
+	}
+	return true, o.WriteTypedMap(TypedMap{Type: c.Class, Entries: entries})
+}
+
+// SetClassRegistry set class registry, default DefaultClassRegistry
+func (o *SerializerV1) SetClassRegistry(r *ClassRegistry) {
+	o.classes = r
+}
+
+// SetClassRegistry set class registry, default DefaultClassRegistry
+func (i *DeserializerV1) SetClassRegistry(r *ClassRegistry) {
+	i.classes = r
+}
@@ -0,0 +1,202 @@
+package hessian
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// classDecimal stand-in of java.math.BigDecimal
+type classDecimal struct {
+	s string
+}
+
+var classDecimalCodec = ClassCodec{
+	Class: "java.math.BigDecimal",
+	Type:  reflect.TypeOf(classDecimal{}),
+	Encode: func(v interface{}) ([]MapEntry, error) {
+		return []MapEntry{{Key: "value", Value: v.(classDecimal).s}}, nil
+	},
+	Decode: func(m TypedMap) (interface{}, error) {
+		v, _ := m.Get("value")
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("value is not a string")
+		}
+		return classDecimal{s: s}, nil
+	},
+}
+
+func TestClassRegistry_Register(t *testing.T) {
+	tests := []struct {
+		name    string
+		c       ClassCodec
+		wantErr bool
+	}{
+		{name: "ok", c: classDecimalCodec, wantErr: false},
+		{name: "no class", c: ClassCodec{Type: classDecimalCodec.Type, Encode: classDecimalCodec.Encode, Decode: classDecimalCodec.Decode}, wantErr: true},
+		{name: "no type", c: ClassCodec{Class: "a.B", Encode: classDecimalCodec.Encode, Decode: classDecimalCodec.Decode}, wantErr: true},
+		{name: "no decode", c: ClassCodec{Class: "a.B", Type: classDecimalCodec.Type, Encode: classDecimalCodec.Encode}, wantErr: true},
+		{name: "decode only", c: ClassCodec{Class: "a.B", Type: classDecimalCodec.Type, Decode: classDecimalCodec.Decode}, wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := NewClassRegistry().Register(tt.c); (err != nil) != tt.wantErr {
+				t.Errorf("ClassRegistry.Register() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// replace the codec of the same type, the class is still decoded
+	r := NewClassRegistry()
+	r.Register(classDecimalCodec)
+	c := classDecimalCodec
+	c.Class = "lab.ggw.Decimal"
+	r.Register(c)
+	if _, ok := r.byClass("java.math.BigDecimal"); !ok {
+		t.Errorf("ClassRegistry.byClass() not found java.math.BigDecimal")
+	}
+	if got, ok := r.byType(c.Type); !ok || got.Class != "lab.ggw.Decimal" {
+		t.Errorf("ClassRegistry.byType() = %v, want lab.ggw.Decimal", got)
+	}
+
+	// decode only codec is not used to encode
+	c.Class = "lab.ggw.Money"
+	c.Encode = nil
+	r.Register(c)
+	if got, _ := r.byType(c.Type); got.Class != "lab.ggw.Decimal" {
+		t.Errorf("ClassRegistry.byType() = %v, want lab.ggw.Decimal", got)
+	}
+}
+
+func TestClassRegistry_Register_Replace(t *testing.T) {
+	type otherDecimal string
+	other := ClassCodec{
+		Class: classDecimalCodec.Class,
+		Type:  reflect.TypeOf(otherDecimal("")),
+		Encode: func(v interface{}) ([]MapEntry, error) {
+			return []MapEntry{{Key: "value", Value: string(v.(otherDecimal))}}, nil
+		},
+		Decode: func(m TypedMap) (interface{}, error) { return otherDecimal(""), nil },
+	}
+	decodeOnly := classDecimalCodec
+	decodeOnly.Encode = nil
+
+	tests := []struct {
+		name     string
+		c        ClassCodec
+		wantType reflect.Type
+	}{
+		{name: "another type", c: other, wantType: other.Type},
+		{name: "without Encode", c: decodeOnly, wantType: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewClassRegistry()
+			r.Register(classDecimalCodec)
+			r.Register(tt.c)
+
+			if got, ok := r.byType(classDecimalCodec.Type); ok {
+				t.Errorf("ClassRegistry.byType() old type = %v, want not found", got.Class)
+			}
+			if tt.wantType != nil {
+				if got, ok := r.byType(tt.wantType); !ok || got.Class != tt.c.Class {
+					t.Errorf("ClassRegistry.byType() new type = %v, %v", got, ok)
+				}
+			}
+
+			o := NewSerializerV1()
+			o.SetClassRegistry(r)
+			if ok, _ := o.writeClass(classDecimal{s: "1"}); ok {
+				t.Errorf("SerializerV1.writeClass() old type written as replaced class")
+			}
+		})
+	}
+}
+
+func TestClassCodec(t *testing.T) {
+	r := NewClassRegistry()
+	if err := r.Register(classDecimalCodec); err != nil {
+		t.Fatal(err)
+	}
+	codec, err := NewCodec(&CodecConfig{Version: V1, Classes: r})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wire := []byte{
+		'M', 't', 0, 20, 'j', 'a', 'v', 'a', '.', 'm', 'a', 't', 'h', '.', 'B', 'i', 'g', 'D', 'e', 'c', 'i', 'm', 'a', 'l',
+		'S', 0, 5, 'v', 'a', 'l', 'u', 'e', 'S', 0, 4, '1', '.', '5', '0',
+		'z',
+	}
+
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{name: "value", v: classDecimal{s: "1.50"}},
+		{name: "pointer", v: &classDecimal{s: "1.50"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := codec.Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, wire) {
+				t.Errorf("Codec.Marshal() = %q, want %q", got, wire)
+			}
+		})
+	}
+
+	if got, _ := codec.Marshal((*classDecimal)(nil)); !reflect.DeepEqual(got, []byte{'N'}) {
+		t.Errorf("Codec.Marshal() = %q, want N", got)
+	}
+
+	var v interface{}
+	if err := codec.Unmarshal(wire, &v); err != nil {
+		t.Fatal(err)
+	}
+	if want := (classDecimal{s: "1.50"}); !reflect.DeepEqual(v, want) {
+		t.Errorf("Codec.Unmarshal() = %#v, want %#v", v, want)
+	}
+
+	var d *classDecimal
+	if err := codec.Unmarshal(wire, &d); err != nil || d.s != "1.50" {
+		t.Errorf("Codec.Unmarshal() = %v, %v", d, err)
+	}
+
+	// nested in a list
+	var list []interface{}
+	p, _ := codec.Marshal([]classDecimal{{s: "1"}})
+	if err := codec.Unmarshal(p, &list); err != nil || !reflect.DeepEqual(list, []interface{}{classDecimal{s: "1"}}) {
+		t.Errorf("Codec.Unmarshal() = %#v, %v", list, err)
+	}
+
+	// invalid fields
+	bad := []byte{'M', 't', 0, 20, 'j', 'a', 'v', 'a', '.', 'm', 'a', 't', 'h', '.', 'B', 'i', 'g', 'D', 'e', 'c', 'i', 'm', 'a', 'l', 'z'}
+	if err := codec.Unmarshal(bad, &v); err == nil {
+		t.Errorf("Codec.Unmarshal() error = nil, want error")
+	}
+
+	// the registry is not consulted when types are kept
+	i := NewDeserializerV1()
+	i.SetClassRegistry(r)
+	i.SetKeepTypes(true)
+	got, _, err := i.ReadValueAt(wire, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (TypedMap{Type: "java.math.BigDecimal", Entries: []MapEntry{{Key: "value", Value: "1.50"}}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("DeserializerV1.ReadValueAt() = %#v, want %#v", got, want)
+	}
+
+	// not registered
+	empty, _ := NewCodec(&CodecConfig{Version: V1, Classes: NewClassRegistry()})
+	if err := empty.Unmarshal(wire, &v); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[interface{}]interface{}{"value": "1.50"}; !reflect.DeepEqual(v, want) {
+		t.Errorf("Codec.Unmarshal() = %#v, want %#v", v, want)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	hessian "github.com/ggwhite/go-hessian"
+)
+
+const callUsage = `Usage:
+
+	hessian call [flags] URL method [argument...]
+
+Each argument is a JSON value. Numbers are int when they fit in 32 bits, long when
+they are bigger, and double when they have a fraction. Typed values are objects:
+
+	{"$int": 1}                          int
+	{"$long": 1} or {"$long": "1"}       long
+	{"$double": 1}                       double
+	{"$date": "2019-04-18T16:20:52Z"}    date, RFC 3339 or milliseconds
+	{"$binary": "aGVsbG8="}              binary, base64
+	{"$class": "lab.ggw.User", "name": "ggwhite"}
+	                                     object of the java class with fields
+	{"$class": "x.Y", "$map": [[1, "a"]]}
+	                                     map with keys other than string
+	{"$list": [1, 2], "$type": "[int"}   list with list type
+
+Flags:
+
+`
+
+// headerFlag repeatable -H "Key: Value" flag
+type headerFlag http.Header
+
+func (h headerFlag) String() string {
+	return ""
+}
+
+func (h headerFlag) Set(v string) error {
+	i := strings.Index(v, ":")
+	if i <= 0 {
+		return fmt.Errorf("header must be \"Key: Value\"")
+	}
+	http.Header(h).Add(strings.TrimSpace(v[:i]), strings.TrimSpace(v[i+1:]))
+	return nil
+}
+
+// runCall the call command
+func runCall(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("call", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, callUsage)
+		fs.PrintDefaults()
+	}
+
+	header := headerFlag(http.Header{})
+	fs.Var(header, "H", "HTTP header \"Key: Value\", repeatable")
+	basic := fs.String("basic", "", "basic authentication \"user:password\"")
+	bearer := fs.String("bearer", "", "bearer token")
+	timeout := fs.Duration("timeout", 30*time.Second, "request timeout")
+	mangle := fs.String("mangle", "", "method name mangling: arity or type")
+	indent := fs.Bool("indent", true, "indent JSON output")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return 2
+	}
+
+	conf := &hessian.ProxyConfig{
+		Version: hessian.V1,
+		URL:     fs.Arg(0),
+		Client:  &http.Client{Timeout: *timeout},
+		Header:  http.Header(header),
+	}
+	switch *mangle {
+	default:
+		fmt.Fprintf(stderr, "hessian call: unknown mangle %q\n", *mangle)
+		return 2
+	case "":
+	case "arity":
+		conf.Mangle = hessian.MangleArity
+	case "type":
+		conf.Mangle = hessian.MangleType
+	}
+	if len(*basic) > 0 {
+		i := strings.Index(*basic, ":")
+		if i < 0 {
+			fmt.Fprintf(stderr, "hessian call: basic must be \"user:password\"\n")
+			return 2
+		}
+		conf.Credentials = &hessian.BasicAuth{Username: (*basic)[:i], Password: (*basic)[i+1:]}
+	}
+	if len(*bearer) > 0 {
+		conf.Credentials = &hessian.BearerAuth{Token: *bearer}
+	}
+
+	var values []interface{}
+	for i, arg := range fs.Args()[2:] {
+		v, err := hessian.FromJSON([]byte(arg))
+		if err != nil {
+			fmt.Fprintf(stderr, "hessian call: argument %d: %v\n", i+1, err)
+			return 2
+		}
+		values = append(values, v)
+	}
+
+	proxy, err := hessian.NewProxy(conf)
+	if err != nil {
+		fmt.Fprintf(stderr, "hessian call: %v\n", err)
+		return 1
+	}
+
+	ans, err := proxy.InvokeContext(context.Background(), fs.Arg(1), values...)
+	if err != nil {
+		fmt.Fprintf(stderr, "hessian call: %v\n", err)
+		return 1
+	}
+
+	var reply interface{}
+	if len(ans) == 1 {
+		reply = hessian.PlainValue(ans[0])
+	} else {
+		reply = hessian.PlainValue(ans)
+	}
+
+	enc := json.NewEncoder(stdout)
+	if *indent {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(reply); err != nil {
+		fmt.Fprintf(stderr, "hessian call: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRunCall(t *testing.T) {
+	var gotBody []byte
+	var gotHeader http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = ioutil.ReadAll(r.Body)
+		gotHeader = r.Header
+		// r{"name": "ggwhite"}
+		w.Write([]byte{'r', 0x01, 0x00, 'M', 't', 0x00, 0x00, 'S', 0x00, 0x04, 'n', 'a', 'm', 'e', 'S', 0x00, 0x07, 'g', 'g', 'w', 'h', 'i', 't', 'e', 'z', 'z'})
+	}))
+	defer srv.Close()
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"call", "-H", "X-Trace: abc", "-basic", "user:pass", "-indent=false", srv.URL, "getUser", `{"$long": 1}`}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("run() = %d, stderr %s", code, stderr.String())
+	}
+	if got, want := stdout.String(), "{\"name\":\"ggwhite\"}\n"; got != want {
+		t.Errorf("run() stdout = %q, want %q", got, want)
+	}
+	if !bytes.Contains(gotBody, []byte{'L', 0, 0, 0, 0, 0, 0, 0, 1}) {
+		t.Errorf("request body = %q, want long argument", gotBody)
+	}
+	if gotHeader.Get("X-Trace") != "abc" {
+		t.Errorf("request header X-Trace = %q, want %q", gotHeader.Get("X-Trace"), "abc")
+	}
+	if user, pass, _ := (&http.Request{Header: gotHeader}).BasicAuth(); user != "user" || pass != "pass" {
+		t.Errorf("request basic auth = %q:%q, want user:pass", user, pass)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "no command", args: nil, want: 2},
+		{name: "unknown command", args: []string{"foo"}, want: 2},
+		{name: "missing method", args: []string{"call", srv.URL}, want: 2},
+		{name: "invalid argument", args: []string{"call", srv.URL, "getUser", "{"}, want: 2},
+		{name: "invalid mangle", args: []string{"call", "-mangle", "foo", srv.URL, "getUser"}, want: 2},
+		{name: "invalid header", args: []string{"call", "-H", "foo", srv.URL, "getUser"}, want: 2},
+		{name: "invalid url", args: []string{"call", "http://127.0.0.1:0", "getUser"}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if got := run(tt.args, strings.NewReader(""), &stdout, &stderr); got != tt.want {
+				t.Errorf("run() = %v, want %v, stderr %s", got, tt.want, stderr.String())
+			}
+		})
+	}
+}
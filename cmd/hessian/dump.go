@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	hessian "github.com/ggwhite/go-hessian"
+)
+
+const dumpUsage = `Usage:
+
+	hessian dump [flags] [file]
+
+Print an annotated tree of a hessian payload read from file or stdin, with offsets,
+tags, lengths, class names and list types. Decoding stops at the first malformed or
+truncated value, which is marked with "^".
+
+Flags:
+
+`
+
+// runDump the dump command
+func runDump(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("dump", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, dumpUsage)
+		fs.PrintDefaults()
+	}
+
+	isHex := fs.Bool("hex", false, "input is hex text, spaces and newlines are ignored")
+	ver := fs.Int("version", 1, "hessian version of the payload")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return 2
+	}
+
+	var v = hessian.V1
+	switch *ver {
+	default:
+		fmt.Fprintf(stderr, "hessian dump: unknown version %d\n", *ver)
+		return 2
+	case 1:
+	case 2:
+		v = hessian.V2
+	}
+
+	r := stdin
+	if fs.NArg() == 1 && fs.Arg(0) != "-" {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintf(stderr, "hessian dump: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		r = f
+	}
+
+	p, err := ioutil.ReadAll(r)
+	if err != nil {
+		fmt.Fprintf(stderr, "hessian dump: %v\n", err)
+		return 1
+	}
+	if *isHex {
+		if p, err = hex.DecodeString(strings.Join(strings.Fields(string(p)), "")); err != nil {
+			fmt.Fprintf(stderr, "hessian dump: %v\n", err)
+			return 1
+		}
+	}
+
+	if err := hessian.Dump(stdout, p, v); err != nil {
+		fmt.Fprintf(stderr, "hessian %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunDump(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hessian")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "reply.bin")
+	if err := ioutil.WriteFile(file, []byte{'r', 0x01, 0x00, 'I', 0x00, 0x00, 0x00, 0x0c, 'z'}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reply := "000000  r reply 1.0\n000003    I int 12\n000008  z end of reply, 1 values\n"
+
+	tests := []struct {
+		name       string
+		args       []string
+		stdin      string
+		want       int
+		wantStdout string
+	}{
+		{name: "file", args: []string{file}, want: 0, wantStdout: reply},
+		{name: "hex stdin", args: []string{"-hex"}, stdin: "72 01 00\n49 0000000c 7a\n", want: 0, wantStdout: reply},
+		{name: "truncated", args: []string{"-hex", "-"}, stdin: "720100 49 0000", want: 1, wantStdout: "000000  r reply 1.0\n000004    ^ stopped: truncated, need 4 bytes, 2 left\n"},
+		{name: "invalid hex", args: []string{"-hex"}, stdin: "zz", want: 1},
+		{name: "unknown version", args: []string{"-version", "3"}, want: 2},
+		{name: "V2", args: []string{"-version", "2"}, stdin: "N", want: 1},
+		{name: "missing file", args: []string{filepath.Join(dir, "missing")}, want: 1},
+		{name: "too many files", args: []string{file, file}, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if got := run(append([]string{"dump"}, tt.args...), strings.NewReader(tt.stdin), &stdout, &stderr); got != tt.want {
+				t.Errorf("run() = %v, want %v, stderr %s", got, tt.want, stderr.String())
+			}
+			if got := stdout.String(); got != tt.wantStdout {
+				t.Errorf("run() stdout = %q, want %q", got, tt.wantStdout)
+			}
+		})
+	}
+}
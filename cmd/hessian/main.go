@@ -0,0 +1,69 @@
+// Command hessian is a command-line tool for hessian services.
+//
+// Usage:
+//
+//	hessian <command> [flags] [arguments]
+//
+// The commands are:
+//
+//	call    invoke a method of hessian service, print reply as JSON
+//	dump    print an annotated tree of a hessian payload
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// command a sub command of hessian
+type command struct {
+	name  string
+	short string
+	run   func(args []string, stdin io.Reader, stdout, stderr io.Writer) int
+}
+
+var commands = []*command{
+	{
+		name:  "call",
+		short: "invoke a method of hessian service, print reply as JSON",
+		run:   runCall,
+	},
+	{
+		name:  "dump",
+		short: "print an annotated tree of a hessian payload",
+		run:   runDump,
+	},
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage:\n\n\thessian <command> [flags] [arguments]\n\nThe commands are:\n\n")
+	for _, c := range commands {
+		fmt.Fprintf(w, "\t%-8s%s\n", c.name, c.short)
+	}
+	fmt.Fprintf(w, "\nUse \"hessian <command> -h\" for more information about a command.\n")
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run dispatch args to sub command, return exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:], stdin, stdout, stderr)
+		}
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stdout)
+		return 0
+	}
+	fmt.Fprintf(stderr, "hessian: unknown command %q\n", args[0])
+	usage(stderr)
+	return 2
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/printer"
+	"go/token"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const hessianPath = "github.com/ggwhite/go-hessian"
+
+// reserved identifiers used by generated code
+var reserved = map[string]bool{"c": true, "ans": true, "err": true, "context": true, "hessian": true}
+
+// annotation find method name in doc comment, ex: // GetUser hessian:"getUser"
+var annotation = regexp.MustCompile(`hessian:"([^"]+)"`)
+
+// method a method of the interface
+type method struct {
+	name    string
+	remote  string
+	params  []param
+	result  string
+	hasCtx  bool
+	ctxName string
+}
+
+// param a named parameter
+type param struct {
+	name string
+	typ  string
+}
+
+// generator generate typed clients of interfaces in a package
+type generator struct {
+	fset  *token.FileSet
+	pkg   string
+	buf   bytes.Buffer
+	paths map[string]string
+}
+
+// generate emit client code of given interface names from parsed files
+func generate(fset *token.FileSet, files []*ast.File, types []string) ([]byte, error) {
+	g := &generator{
+		fset:  fset,
+		paths: make(map[string]string),
+	}
+
+	var body bytes.Buffer
+	for _, name := range types {
+		spec, file := findInterface(files, name)
+		if spec == nil {
+			return nil, fmt.Errorf("interface %s not found", name)
+		}
+		g.pkg = file.Name.Name
+
+		methods, err := g.methods(file, spec.Type.(*ast.InterfaceType))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		g.client(&body, name, methods)
+	}
+
+	g.header()
+	g.buf.Write(body.Bytes())
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %v", err)
+	}
+	return src, nil
+}
+
+// findInterface find the interface type spec by name
+func findInterface(files []*ast.File, name string) (*ast.TypeSpec, *ast.File) {
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, s := range gd.Specs {
+				ts := s.(*ast.TypeSpec)
+				if _, ok := ts.Type.(*ast.InterfaceType); ok && ts.Name.Name == name {
+					return ts, file
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// methods parse methods of the interface
+func (g *generator) methods(file *ast.File, it *ast.InterfaceType) ([]method, error) {
+	var ans []method
+
+	for _, f := range it.Methods.List {
+		ft, ok := f.Type.(*ast.FuncType)
+		if !ok || len(f.Names) == 0 {
+			return nil, fmt.Errorf("embedded interface is unsupported")
+		}
+		m := method{
+			name:   f.Names[0].Name,
+			remote: f.Names[0].Name,
+		}
+		if f.Doc != nil {
+			if sub := annotation.FindStringSubmatch(f.Doc.Text()); sub != nil {
+				m.remote = sub[1]
+			}
+		}
+
+		// parameters
+		var idx int
+		for _, p := range ft.Params.List {
+			if _, ok := p.Type.(*ast.Ellipsis); ok {
+				return nil, fmt.Errorf("%s: variadic parameter is unsupported", m.name)
+			}
+			typ := g.expr(file, p.Type)
+			names := p.Names
+			if len(names) == 0 {
+				names = []*ast.Ident{nil}
+			}
+			for _, n := range names {
+				name := "a" + strconv.Itoa(idx)
+				if n != nil && n.Name != "_" && !reserved[n.Name] {
+					name = n.Name
+				}
+				if idx == 0 && typ == "context.Context" {
+					m.hasCtx = true
+					m.ctxName = name
+				} else {
+					m.params = append(m.params, param{name: name, typ: typ})
+				}
+				idx++
+			}
+		}
+
+		// results, must be error or (value, error)
+		var results []string
+		if ft.Results != nil {
+			for _, r := range ft.Results.List {
+				n := len(r.Names)
+				if n == 0 {
+					n = 1
+				}
+				for k := 0; k < n; k++ {
+					results = append(results, g.expr(file, r.Type))
+				}
+			}
+		}
+		if len(results) == 0 || len(results) > 2 || results[len(results)-1] != "error" {
+			return nil, fmt.Errorf("%s: method must return error or (value, error)", m.name)
+		}
+		if len(results) == 2 {
+			m.result = results[0]
+		}
+
+		ans = append(ans, m)
+	}
+
+	return ans, nil
+}
+
+// expr print type expression and record imports it uses
+func (g *generator) expr(file *ast.File, e ast.Expr) string {
+	ast.Inspect(e, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if id, ok := sel.X.(*ast.Ident); ok {
+			if p := importPath(file, id.Name); len(p) > 0 {
+				g.paths[id.Name] = p
+			}
+		}
+		return false
+	})
+
+	var buf bytes.Buffer
+	printer.Fprint(&buf, g.fset, e)
+	return buf.String()
+}
+
+// importPath find import path of package name in file
+func importPath(file *ast.File, name string) string {
+	for _, spec := range file.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		if spec.Name != nil {
+			if spec.Name.Name == name {
+				return p
+			}
+			continue
+		}
+		base := path.Base(p)
+		base = strings.TrimPrefix(base, "go-")
+		if i := strings.Index(base, ".v"); i > 0 {
+			base = base[:i]
+		}
+		if base == name {
+			return p
+		}
+	}
+	return ""
+}
+
+// header write package clause and imports
+func (g *generator) header() {
+	fmt.Fprintf(&g.buf, "// Code generated by hessiangen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&g.buf, "package %s\n\n", g.pkg)
+
+	g.paths["hessian"] = hessianPath
+
+	// standard library first, then others
+	var std, others []string
+	for name, p := range g.paths {
+		if strings.Contains(strings.SplitN(p, "/", 2)[0], ".") {
+			others = append(others, name)
+		} else {
+			std = append(std, name)
+		}
+	}
+
+	fmt.Fprintf(&g.buf, "import (\n")
+	for i, names := range [][]string{std, others} {
+		if i > 0 && len(names) > 0 {
+			fmt.Fprintf(&g.buf, "\n")
+		}
+		sort.Slice(names, func(a, b int) bool { return g.paths[names[a]] < g.paths[names[b]] })
+		for _, name := range names {
+			p := g.paths[name]
+			if path.Base(p) == name {
+				fmt.Fprintf(&g.buf, "\t%q\n", p)
+			} else {
+				fmt.Fprintf(&g.buf, "\t%s %q\n", name, p)
+			}
+		}
+	}
+	fmt.Fprintf(&g.buf, ")\n\n")
+}
+
+// client write client type of the interface
+func (g *generator) client(w *bytes.Buffer, name string, methods []method) {
+	client := name + "Client"
+
+	fmt.Fprintf(w, "// %s hessian client of %s, it is safe for concurrent use as hessian.Proxy\n", client, name)
+	fmt.Fprintf(w, "type %s struct {\n\tproxy *hessian.Proxy\n}\n\n", client)
+	fmt.Fprintf(w, "var _ %s = (*%s)(nil)\n\n", name, client)
+	fmt.Fprintf(w, "// New%s create %s by given proxy\n", client, client)
+	fmt.Fprintf(w, "func New%s(proxy *hessian.Proxy) *%s {\n\treturn &%s{proxy: proxy}\n}\n\n", client, client, client)
+
+	if len(methods) > 0 {
+		// every method call with a context
+		g.paths["context"] = "context"
+	}
+	for _, m := range methods {
+		var params, args []string
+		ctx := "context.Background()"
+		if m.hasCtx {
+			ctx = m.ctxName
+			params = append(params, m.ctxName+" context.Context")
+		}
+		for _, p := range m.params {
+			params = append(params, p.name+" "+p.typ)
+			args = append(args, p.name)
+		}
+
+		call := strconv.Quote(m.remote)
+		if len(args) > 0 {
+			call += ", " + strings.Join(args, ", ")
+		}
+
+		fmt.Fprintf(w, "// %s invoke hessian method %s\n", m.name, m.remote)
+		if len(m.result) == 0 {
+			fmt.Fprintf(w, "func (c *%s) %s(%s) error {\n", client, m.name, strings.Join(params, ", "))
+			fmt.Fprintf(w, "\treturn c.proxy.InvokeInto(%s, nil, %s)\n}\n\n", ctx, call)
+			continue
+		}
+		fmt.Fprintf(w, "func (c *%s) %s(%s) (%s, error) {\n", client, m.name, strings.Join(params, ", "), m.result)
+		fmt.Fprintf(w, "\tvar ans %s\n", m.result)
+		fmt.Fprintf(w, "\terr := c.proxy.InvokeInto(%s, &ans, %s)\n", ctx, call)
+		fmt.Fprintf(w, "\treturn ans, err\n}\n\n")
+	}
+}
+
+// outputName default output file name of the types, ex: UserService -> userservice_hessian.go
+func outputName(types []string) string {
+	return strings.ToLower(strings.Join(types, "_")) + "_hessian.go"
+}
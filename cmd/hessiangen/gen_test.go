@@ -0,0 +1,145 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		types    []string
+		contains []string
+		excludes []string
+		wantErr  bool
+	}{
+		{
+			name: "Happy Pass",
+			src: `package demo
+
+import (
+	"context"
+	"time"
+
+	hessian "github.com/ggwhite/go-hessian"
+)
+
+type User struct {
+	hessian.Package ` + "`hessian:\"lab.ggw.shs.service.User\"`" + `
+	Name string ` + "`hessian:\"name\"`" + `
+}
+
+type UserService interface {
+	// GetUser hessian:"getUser"
+	GetUser(ctx context.Context, id int64) (*User, error)
+	Now() (time.Time, error)
+	Ping(string, int32) error
+	Err(c int32) (ans []string, err error)
+}
+`,
+			types: []string{"UserService"},
+			contains: []string{
+				"// Code generated by hessiangen. DO NOT EDIT.",
+				"package demo",
+				`"context"`,
+				`"time"`,
+				`hessian "github.com/ggwhite/go-hessian"`,
+				"// UserServiceClient hessian client of UserService, it is safe for concurrent use as hessian.Proxy",
+				"type UserServiceClient struct",
+				"var _ UserService = (*UserServiceClient)(nil)",
+				"func NewUserServiceClient(proxy *hessian.Proxy) *UserServiceClient",
+				"func (c *UserServiceClient) GetUser(ctx context.Context, id int64) (*User, error)",
+				`err := c.proxy.InvokeInto(ctx, &ans, "getUser", id)`,
+				`err := c.proxy.InvokeInto(context.Background(), &ans, "Now")`,
+				"func (c *UserServiceClient) Ping(a0 string, a1 int32) error",
+				`return c.proxy.InvokeInto(context.Background(), nil, "Ping", a0, a1)`,
+				"func (c *UserServiceClient) Err(a0 int32) ([]string, error)",
+			},
+			wantErr: false,
+		},
+		{
+			name: "No Methods",
+			src: `package demo
+
+type Empty interface{}
+`,
+			types:    []string{"Empty"},
+			contains: []string{"type EmptyClient struct", "var _ Empty = (*EmptyClient)(nil)"},
+			excludes: []string{`"context"`},
+			wantErr:  false,
+		},
+		{
+			name:    "Not Found",
+			src:     "package demo\n",
+			types:   []string{"UserService"},
+			wantErr: true,
+		},
+		{
+			name: "Without Error",
+			src: `package demo
+
+type UserService interface {
+	Name() string
+}
+`,
+			types:   []string{"UserService"},
+			wantErr: true,
+		},
+		{
+			name: "Variadic",
+			src: `package demo
+
+type UserService interface {
+	Names(...string) error
+}
+`,
+			types:   []string{"UserService"},
+			wantErr: true,
+		},
+		{
+			name: "Embedded",
+			src: `package demo
+
+type UserService interface {
+	Other
+}
+`,
+			types:   []string{"UserService"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, "demo.go", tt.src, parser.ParseComments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := generate(fset, []*ast.File{f}, tt.types)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("generate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if _, err := parser.ParseFile(token.NewFileSet(), "out.go", got, 0); err != nil {
+				t.Errorf("generate() output is invalid: %v\n%s", err, got)
+			}
+			for _, c := range tt.contains {
+				if !strings.Contains(string(got), c) {
+					t.Errorf("generate() output without %q\n%s", c, got)
+				}
+			}
+			for _, c := range tt.excludes {
+				if strings.Contains(string(got), c) {
+					t.Errorf("generate() output with %q\n%s", c, got)
+				}
+			}
+		})
+	}
+}
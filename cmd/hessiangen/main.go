@@ -0,0 +1,107 @@
+// Command hessiangen generate typed hessian clients from Go interface definitions.
+//
+// Usage:
+//
+//	//go:generate hessiangen -type UserService
+//	type UserService interface {
+//		// GetUser hessian:"getUser"
+//		GetUser(ctx context.Context, id int64) (*User, error)
+//		Ping() error
+//	}
+//
+// It emits UserServiceClient with NewUserServiceClient(*hessian.Proxy), each method invoke the
+// hessian method named by the `hessian:"name"` annotation in doc comment, or the method name.
+// Methods must return error or (value, error), the first parameter can be a context.Context.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	typeNames = flag.String("type", "", "comma-separated list of interface names; must be set")
+	output    = flag.String("output", "", "output file name; default <type>_hessian.go")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage of hessiangen:\n")
+	fmt.Fprintf(os.Stderr, "\thessiangen -type T [directory | files...]\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("hessiangen: ")
+	flag.Usage = usage
+	flag.Parse()
+
+	if len(*typeNames) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	types := strings.Split(*typeNames, ",")
+
+	args := flag.Args()
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+
+	fset := token.NewFileSet()
+	files, dir, err := parseFiles(fset, args)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := generate(fset, files, types)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	name := *output
+	if len(name) == 0 {
+		name = filepath.Join(dir, outputName(types))
+	}
+	if err := ioutil.WriteFile(name, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parseFiles parse a directory or a list of files, return files and their directory
+func parseFiles(fset *token.FileSet, args []string) ([]*ast.File, string, error) {
+	var files []*ast.File
+
+	if len(args) == 1 {
+		if info, err := os.Stat(args[0]); err == nil && info.IsDir() {
+			pkgs, err := parser.ParseDir(fset, args[0], func(info os.FileInfo) bool {
+				return !strings.HasSuffix(info.Name(), "_test.go")
+			}, parser.ParseComments)
+			if err != nil {
+				return nil, "", err
+			}
+			for _, pkg := range pkgs {
+				for _, f := range pkg.Files {
+					files = append(files, f)
+				}
+			}
+			return files, args[0], nil
+		}
+	}
+
+	for _, name := range args {
+		f, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
+		if err != nil {
+			return nil, "", err
+		}
+		files = append(files, f)
+	}
+	return files, filepath.Dir(args[0]), nil
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"unicode"
+)
+
+// class file access flags
+const (
+	accStatic     = 0x0008
+	accTransient  = 0x0080
+	accInterface  = 0x0200
+	accAnnotation = 0x2000
+	accEnum       = 0x4000
+)
+
+// constant pool tags
+const (
+	cpUtf8               = 1
+	cpInteger            = 3
+	cpFloat              = 4
+	cpLong               = 5
+	cpDouble             = 6
+	cpClass              = 7
+	cpString             = 8
+	cpFieldref           = 9
+	cpMethodref          = 10
+	cpInterfaceMethodref = 11
+	cpNameAndType        = 12
+	cpMethodHandle       = 15
+	cpMethodType         = 16
+	cpDynamic            = 17
+	cpInvokeDynamic      = 18
+	cpModule             = 19
+	cpPackage            = 20
+)
+
+// classReader big-endian reader of class file
+type classReader struct {
+	p   []byte
+	pos int
+	err error
+}
+
+func (r *classReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.pos+n > len(r.p) {
+		r.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := r.p[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *classReader) u1() int {
+	if b := r.bytes(1); b != nil {
+		return int(b[0])
+	}
+	return 0
+}
+
+func (r *classReader) u2() int {
+	if b := r.bytes(2); b != nil {
+		return int(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (r *classReader) u4() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+// parseClass parse a class file, return nil class for interfaces, annotations,
+// anonymous and module/package info classes which are not data types
+func parseClass(p []byte) (*javaClass, error) {
+	r := &classReader{p: p}
+	if r.u4() != 0xCAFEBABE {
+		return nil, fmt.Errorf("invalid class file magic")
+	}
+	r.u2() // minor
+	r.u2() // major
+
+	// constant pool, only utf8 and class entries are used
+	n := r.u2()
+	utf8 := make(map[int]string)
+	classes := make(map[int]int)
+	for i := 1; i < n && r.err == nil; i++ {
+		switch tag := r.u1(); tag {
+		case cpUtf8:
+			utf8[i] = string(r.bytes(r.u2()))
+		case cpClass:
+			classes[i] = r.u2()
+		case cpString, cpMethodType, cpModule, cpPackage:
+			r.u2()
+		case cpMethodHandle:
+			r.bytes(3)
+		case cpInteger, cpFloat, cpFieldref, cpMethodref, cpInterfaceMethodref, cpNameAndType, cpDynamic, cpInvokeDynamic:
+			r.bytes(4)
+		case cpLong, cpDouble:
+			r.bytes(8)
+			i++
+		default:
+			return nil, fmt.Errorf("unknown constant pool tag %d", tag)
+		}
+	}
+	className := func(idx int) string {
+		return strings.Replace(utf8[classes[idx]], "/", ".", -1)
+	}
+
+	access := r.u2()
+	name := className(r.u2())
+	super := r.u2()
+	r.bytes(2 * r.u2()) // interfaces
+
+	c := &javaClass{
+		Name:     name,
+		Enum:     access&accEnum != 0,
+		resolved: true,
+	}
+	if super != 0 {
+		c.Super = className(super)
+	}
+
+	for i, l := 0, r.u2(); i < l && r.err == nil; i++ {
+		flags := r.u2()
+		fname := utf8[r.u2()]
+		desc := utf8[r.u2()]
+
+		var signature string
+		for j, m := 0, r.u2(); j < m && r.err == nil; j++ {
+			attr := utf8[r.u2()]
+			data := r.bytes(int(r.u4()))
+			if attr == "Signature" && len(data) == 2 {
+				signature = utf8[int(binary.BigEndian.Uint16(data))]
+			}
+		}
+
+		if flags&accEnum != 0 && flags&accStatic != 0 {
+			c.Constants = append(c.Constants, fname)
+			continue
+		}
+		// the same as java hessian JavaSerializer
+		if flags&(accStatic|accTransient) != 0 {
+			continue
+		}
+
+		if len(signature) == 0 {
+			signature = desc
+		}
+		t, rest, err := parseSignature(signature)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %v", name, fname, err)
+		}
+		if len(rest) > 0 {
+			return nil, fmt.Errorf("%s.%s: invalid signature %s", name, fname, signature)
+		}
+		c.Fields = append(c.Fields, javaField{Name: fname, Type: t})
+	}
+
+	if r.err != nil {
+		return nil, fmt.Errorf("%s: %v", name, r.err)
+	}
+
+	// methods and class attributes are not used
+	if access&(accInterface|accAnnotation) != 0 || isAnonymous(name) {
+		return nil, nil
+	}
+	return c, nil
+}
+
+// isAnonymous check binary name of anonymous or local class (Outer$1, Outer$1Local), and module-info, package-info
+func isAnonymous(name string) bool {
+	simple := name[strings.LastIndex(name, ".")+1:]
+	if simple == "module-info" || simple == "package-info" {
+		return true
+	}
+	if i := strings.LastIndex(simple, "$"); i >= 0 && i+1 < len(simple) {
+		return unicode.IsDigit(rune(simple[i+1]))
+	}
+	return false
+}
+
+// primitiveDescriptors field descriptor of primitive types
+var primitiveDescriptors = map[byte]string{
+	'B': "byte",
+	'C': "char",
+	'D': "double",
+	'F': "float",
+	'I': "int",
+	'J': "long",
+	'S': "short",
+	'Z': "boolean",
+}
+
+// parseSignature parse a field descriptor or field signature, ex: Ljava/util/List<Llab/ggw/User;>;
+// type variables become "?" and wildcards become their bound or java.lang.Object
+func parseSignature(s string) (javaType, string, error) {
+	var t javaType
+	if len(s) == 0 {
+		return t, s, fmt.Errorf("unexpected end of signature")
+	}
+
+	if name, ok := primitiveDescriptors[s[0]]; ok {
+		return javaType{Name: name}, s[1:], nil
+	}
+
+	switch s[0] {
+	case '[':
+		e, rest, err := parseSignature(s[1:])
+		if err != nil {
+			return t, rest, err
+		}
+		e.Dims++
+		return e, rest, nil
+	case 'T':
+		end := strings.IndexByte(s, ';')
+		if end < 0 {
+			return t, s, fmt.Errorf("invalid type variable %s", s)
+		}
+		return javaType{Name: "?"}, s[end+1:], nil
+	case 'L':
+		s = s[1:]
+		var name strings.Builder
+		for len(s) > 0 {
+			switch s[0] {
+			case ';':
+				t.Name = strings.Replace(name.String(), "/", ".", -1)
+				return t, s[1:], nil
+			case '.':
+				// inner class of generic outer class, ex: Lfoo/Outer<TT;>.Inner;
+				name.WriteByte('$')
+				t.Args = nil
+				s = s[1:]
+			case '<':
+				s = s[1:]
+				for len(s) > 0 && s[0] != '>' {
+					var a javaType
+					var err error
+					switch s[0] {
+					case '*':
+						a, s = javaType{Name: "java.lang.Object"}, s[1:]
+					case '+':
+						a, s, err = parseSignature(s[1:])
+					case '-':
+						_, s, err = parseSignature(s[1:])
+						a = javaType{Name: "java.lang.Object"}
+					default:
+						a, s, err = parseSignature(s)
+					}
+					if err != nil {
+						return t, s, err
+					}
+					t.Args = append(t.Args, a)
+				}
+				if len(s) == 0 {
+					return t, s, fmt.Errorf("unexpected end of type arguments")
+				}
+				s = s[1:]
+			default:
+				name.WriteByte(s[0])
+				s = s[1:]
+			}
+		}
+		return t, s, fmt.Errorf("unexpected end of class type")
+	}
+
+	return t, s, fmt.Errorf("invalid signature %s", s)
+}
+
+// parseJar parse all class files in a jar archive
+func parseJar(r io.ReaderAt, size int64) ([]*javaClass, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var ans []*javaClass
+	for _, f := range zr.File {
+		if !strings.HasSuffix(f.Name, ".class") || strings.HasPrefix(f.Name, "META-INF/") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		p, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f.Name, err)
+		}
+		c, err := parseClass(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f.Name, err)
+		}
+		if c != nil {
+			ans = append(ans, c)
+		}
+	}
+	return ans, nil
+}
+
+// parseJarBytes parse jar from bytes
+func parseJarBytes(p []byte) ([]*javaClass, error) {
+	return parseJar(bytes.NewReader(p), int64(len(p)))
+}
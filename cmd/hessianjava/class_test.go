@@ -0,0 +1,221 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+type testField struct {
+	access    int
+	name      string
+	desc      string
+	signature string
+}
+
+// buildClass assemble a minimal class file
+func buildClass(access int, name, super string, fields []testField) []byte {
+	var pool bytes.Buffer
+	var count = 1
+	utf8 := func(s string) int {
+		pool.WriteByte(cpUtf8)
+		binary.Write(&pool, binary.BigEndian, uint16(len(s)))
+		pool.WriteString(s)
+		count++
+		return count - 1
+	}
+	class := func(s string) int {
+		idx := utf8(s)
+		pool.WriteByte(cpClass)
+		binary.Write(&pool, binary.BigEndian, uint16(idx))
+		count++
+		return count - 1
+	}
+
+	// a long constant takes two entries
+	pool.WriteByte(cpLong)
+	pool.Write(make([]byte, 8))
+	count += 2
+
+	this := class(name)
+	var sup int
+	if len(super) > 0 {
+		sup = class(super)
+	}
+	sigAttr := utf8("Signature")
+
+	var body bytes.Buffer
+	w := func(v int) { binary.Write(&body, binary.BigEndian, uint16(v)) }
+	w(access)
+	w(this)
+	w(sup)
+	w(0) // interfaces
+	w(len(fields))
+	for _, f := range fields {
+		w(f.access)
+		w(utf8(f.name))
+		w(utf8(f.desc))
+		if len(f.signature) == 0 {
+			w(0)
+			continue
+		}
+		w(1)
+		w(sigAttr)
+		binary.Write(&body, binary.BigEndian, uint32(2))
+		w(utf8(f.signature))
+	}
+	w(0) // methods
+	w(0) // attributes
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, uint32(0xCAFEBABE))
+	binary.Write(&buf, binary.BigEndian, uint16(0))
+	binary.Write(&buf, binary.BigEndian, uint16(52))
+	binary.Write(&buf, binary.BigEndian, uint16(count))
+	buf.Write(pool.Bytes())
+	buf.Write(body.Bytes())
+	return buf.Bytes()
+}
+
+func TestParseClass(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       []byte
+		want    *javaClass
+		wantErr bool
+	}{
+		{
+			name: "class",
+			p: buildClass(0x0021, "lab/ggw/dto/User", "lab/ggw/dto/Base", []testField{
+				{access: accStatic, name: "serialVersionUID", desc: "J"},
+				{access: accTransient, name: "cache", desc: "Ljava/lang/String;"},
+				{name: "name", desc: "Ljava/lang/String;"},
+				{name: "age", desc: "I"},
+				{name: "scores", desc: "[[D"},
+				{name: "friends", desc: "Ljava/util/List;", signature: "Ljava/util/List<Llab/ggw/dto/User;>;"},
+				{name: "tags", desc: "Ljava/util/Map;", signature: "Ljava/util/Map<Ljava/lang/String;+Ljava/util/List<*>;>;"},
+				{name: "payload", desc: "Ljava/lang/Object;", signature: "TT;"},
+				{name: "inner", desc: "Llab/ggw/dto/User$Inner;", signature: "Llab/ggw/dto/Outer<TT;>.Inner;"},
+			}),
+			want: &javaClass{
+				Name:  "lab.ggw.dto.User",
+				Super: "lab.ggw.dto.Base",
+				Fields: []javaField{
+					{Name: "name", Type: javaType{Name: "java.lang.String"}},
+					{Name: "age", Type: javaType{Name: "int"}},
+					{Name: "scores", Type: javaType{Name: "double", Dims: 2}},
+					{Name: "friends", Type: javaType{Name: "java.util.List", Args: []javaType{{Name: "lab.ggw.dto.User"}}}},
+					{Name: "tags", Type: javaType{Name: "java.util.Map", Args: []javaType{
+						{Name: "java.lang.String"},
+						{Name: "java.util.List", Args: []javaType{{Name: "java.lang.Object"}}},
+					}}},
+					{Name: "payload", Type: javaType{Name: "?"}},
+					{Name: "inner", Type: javaType{Name: "lab.ggw.dto.Outer$Inner"}},
+				},
+				resolved: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "enum",
+			p: buildClass(0x4031, "lab/ggw/dto/Color", "java/lang/Enum", []testField{
+				{access: 0x4019, name: "RED", desc: "Llab/ggw/dto/Color;"},
+				{access: 0x4019, name: "GREEN", desc: "Llab/ggw/dto/Color;"},
+				{access: 0x101a, name: "$VALUES", desc: "[Llab/ggw/dto/Color;"},
+			}),
+			want: &javaClass{
+				Name:      "lab.ggw.dto.Color",
+				Super:     "java.lang.Enum",
+				Enum:      true,
+				Constants: []string{"RED", "GREEN"},
+				resolved:  true,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "interface",
+			p:       buildClass(0x0601, "lab/ggw/dto/Service", "java/lang/Object", nil),
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:    "anonymous",
+			p:       buildClass(0x0020, "lab/ggw/dto/User$1", "java/lang/Object", nil),
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:    "magic",
+			p:       []byte{0, 1, 2, 3},
+			wantErr: true,
+		},
+		{
+			name:    "truncated",
+			p:       buildClass(0x0021, "lab/ggw/dto/User", "java/lang/Object", []testField{{name: "name", desc: "I"}})[:40],
+			wantErr: true,
+		},
+		{
+			name:    "invalid descriptor",
+			p:       buildClass(0x0021, "lab/ggw/dto/User", "java/lang/Object", []testField{{name: "name", desc: "Q"}}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseClass(tt.p)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseClass() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseClass() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJar(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string][]byte{
+		"META-INF/MANIFEST.MF":           []byte("Manifest-Version: 1.0\n"),
+		"lab/ggw/dto/User.class":         buildClass(0x0021, "lab/ggw/dto/User", "java/lang/Object", []testField{{name: "name", desc: "Ljava/lang/String;"}}),
+		"lab/ggw/dto/Service.class":      buildClass(0x0601, "lab/ggw/dto/Service", "java/lang/Object", nil),
+		"lab/ggw/dto/Account.class":      buildClass(0x0021, "lab/ggw/dto/Account", "java/lang/Object", []testField{{name: "id", desc: "J"}}),
+		"lab/ggw/dto/package-info.class": buildClass(0x1600, "lab/ggw/dto/package-info", "java/lang/Object", nil),
+	}
+	for name, p := range files {
+		w, _ := zw.Create(name)
+		w.Write(p)
+	}
+	zw.Close()
+
+	classes, err := parseJarBytes(buf.Bytes())
+	if err != nil {
+		t.Fatalf("parseJar() error = %v", err)
+	}
+	var names []string
+	for _, c := range classes {
+		names = append(names, c.Name)
+	}
+	if len(names) != 2 {
+		t.Errorf("parseJar() = %v, want User and Account", names)
+	}
+
+	src, err := emit("model", classes)
+	if err != nil {
+		t.Fatalf("emit() error = %v", err)
+	}
+	if !bytes.Contains(src, []byte("type Account struct")) || !bytes.Contains(src, []byte("type User struct")) {
+		t.Errorf("emit() = %s", src)
+	}
+
+	if _, err := parseJarBytes([]byte("not a zip")); err == nil {
+		t.Errorf("parseJar() error = %v, wantErr %v", err, true)
+	}
+}
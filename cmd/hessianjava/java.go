@@ -0,0 +1,461 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// javaType a java type reference, ex: java.util.List<lab.ggw.User>, int[]
+type javaType struct {
+	// Name type name as written (simple or qualified) or resolved full name, ex: List, java.util.List
+	Name string
+	// Args generic type arguments
+	Args []javaType
+	// Dims array dimensions
+	Dims int
+}
+
+// javaField a serializable field of a java class
+type javaField struct {
+	Name string
+	Type javaType
+}
+
+// javaClass a java class or enum
+type javaClass struct {
+	// Name binary class name, ex: lab.ggw.Outer$Inner
+	Name string
+	// Super super class as written, fields of known super class are flattened into the go struct
+	Super string
+	// Imports single type imports of the compilation unit, simple name -> full name
+	Imports map[string]string
+	// Wildcards packages of type-import-on-demand, ex: java.math of import java.math.*
+	Wildcards []string
+	// Vars type variables of the class, mapped to interface{}
+	Vars []string
+	// Enum true if class is a java enum
+	Enum bool
+	// Constants enum constants
+	Constants []string
+	// Fields serializable fields, without static and transient fields
+	Fields []javaField
+	// resolved true if type names of Fields are already full names
+	resolved bool
+}
+
+// pkg package of the class
+func (c *javaClass) pkg() string {
+	if i := strings.LastIndex(c.Name, "."); i >= 0 {
+		return c.Name[:i]
+	}
+	return ""
+}
+
+// primitives java primitive and boxed types to go types, boxed types are pointers at field level
+var primitives = map[string]struct {
+	goType string
+	boxed  bool
+}{
+	"boolean": {"bool", false}, "java.lang.Boolean": {"bool", true},
+	"byte": {"int8", false}, "java.lang.Byte": {"int8", true},
+	"short": {"int16", false}, "java.lang.Short": {"int16", true},
+	"int": {"int32", false}, "java.lang.Integer": {"int32", true},
+	"long": {"int64", false}, "java.lang.Long": {"int64", true},
+	"float": {"float32", false}, "java.lang.Float": {"float32", true},
+	"double": {"float64", false}, "java.lang.Double": {"float64", true},
+	"char": {"string", false}, "java.lang.Character": {"string", true},
+	"java.lang.String":       {"string", false},
+	"java.lang.CharSequence": {"string", false},
+}
+
+// dates java types serialized as hessian date
+var dates = map[string]bool{
+	"java.util.Date":     true,
+	"java.sql.Date":      true,
+	"java.sql.Time":      true,
+	"java.sql.Timestamp": true,
+}
+
+// javaClasses java classes with go types in hessian package, they are pointers at field level as
+// boxed types, unset fields are written as null
+var javaClasses = map[string]string{
+	"java.math.BigDecimal": "hessian.Decimal",
+	"java.math.BigInteger": "*big.Int",
+	"java.util.UUID":       "hessian.UUID",
+	"java.util.Locale":     "hessian.Locale",
+	"java.util.Currency":   "hessian.Currency",
+}
+
+// lists java collection types serialized as hessian list
+var lists = map[string]bool{
+	"java.lang.Iterable":                        true,
+	"java.util.Collection":                      true,
+	"java.util.List":                            true,
+	"java.util.ArrayList":                       true,
+	"java.util.LinkedList":                      true,
+	"java.util.Vector":                          true,
+	"java.util.Set":                             true,
+	"java.util.HashSet":                         true,
+	"java.util.LinkedHashSet":                   true,
+	"java.util.SortedSet":                       true,
+	"java.util.TreeSet":                         true,
+	"java.util.concurrent.CopyOnWriteArrayList": true,
+}
+
+// maps java map types serialized as hessian map
+var maps = map[string]bool{
+	"java.util.Map":                          true,
+	"java.util.HashMap":                      true,
+	"java.util.LinkedHashMap":                true,
+	"java.util.SortedMap":                    true,
+	"java.util.TreeMap":                      true,
+	"java.util.Hashtable":                    true,
+	"java.util.concurrent.ConcurrentMap":     true,
+	"java.util.concurrent.ConcurrentHashMap": true,
+}
+
+// implicit packages to resolve simple names which are not imported
+var implicit = []string{"java.lang", "java.util", "java.sql", "java.util.concurrent"}
+
+// emitter emit go structs of java classes
+type emitter struct {
+	classes  map[string]*javaClass
+	names    map[string]string
+	usesBig  bool
+	usesTime bool
+}
+
+// emit generate go source of the package from classes
+func emit(pkg string, classes []*javaClass) ([]byte, error) {
+	e := &emitter{
+		classes: make(map[string]*javaClass),
+		names:   make(map[string]string),
+	}
+
+	for _, c := range classes {
+		if _, exist := e.classes[c.Name]; exist {
+			return nil, fmt.Errorf("duplicate class %s", c.Name)
+		}
+		e.classes[c.Name] = c
+	}
+	e.goNames()
+
+	sorted := make([]*javaClass, 0, len(classes))
+	sorted = append(sorted, classes...)
+	sort.Slice(sorted, func(a, b int) bool { return e.names[sorted[a].Name] < e.names[sorted[b].Name] })
+
+	var body bytes.Buffer
+	for _, c := range sorted {
+		if err := e.class(&body, c); err != nil {
+			return nil, err
+		}
+	}
+	e.register(&body, sorted)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by hessianjava. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	fmt.Fprintf(&buf, "import (\n")
+	if e.usesBig {
+		fmt.Fprintf(&buf, "\t\"math/big\"\n")
+	}
+	fmt.Fprintf(&buf, "\t\"reflect\"\n")
+	if e.usesTime {
+		fmt.Fprintf(&buf, "\t\"time\"\n")
+	}
+	fmt.Fprintf(&buf, "\n\thessian \"github.com/ggwhite/go-hessian\"\n)\n\n")
+	buf.Write(body.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %v\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
+
+// goNames define go type names of classes, simple name or prefixed by package if duplicated
+func (e *emitter) goNames() {
+	count := make(map[string]int)
+	simple := func(name string) string {
+		name = name[strings.LastIndex(name, ".")+1:]
+		return exported(strings.Replace(name, "$", "", -1))
+	}
+	for name := range e.classes {
+		count[simple(name)]++
+	}
+	for name := range e.classes {
+		n := simple(name)
+		if count[n] > 1 {
+			var prefix string
+			for _, p := range strings.Split(e.classes[name].pkg(), ".") {
+				prefix += exported(p)
+			}
+			n = prefix + n
+		}
+		e.names[name] = n
+	}
+}
+
+// class write go struct of the class
+func (e *emitter) class(w *bytes.Buffer, c *javaClass) error {
+	name := e.names[c.Name]
+
+	if c.Enum {
+		fmt.Fprintf(w, "// %s java enum %s\n", name, c.Name)
+		fmt.Fprintf(w, "type %s string\n\n", name)
+		if len(c.Constants) > 0 {
+			fmt.Fprintf(w, "// constants of %s\n", name)
+			fmt.Fprintf(w, "const (\n")
+			for _, k := range c.Constants {
+				fmt.Fprintf(w, "\t%s %s = %q\n", name+exported(k), name, k)
+			}
+			fmt.Fprintf(w, ")\n\n")
+		}
+		return nil
+	}
+
+	fields, err := e.fields(c, make(map[string]bool))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "// %s java class %s\n", name, c.Name)
+	fmt.Fprintf(w, "type %s struct {\n", name)
+	fmt.Fprintf(w, "\thessian.Package `hessian:%q`\n", c.Name)
+
+	used := map[string]bool{"Package": true}
+	for _, f := range fields {
+		typ, err := e.goType(c, f.Type, true)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %v", c.Name, f.Name, err)
+		}
+		fn := exported(f.Name)
+		for used[fn] {
+			fn += "_"
+		}
+		used[fn] = true
+		fmt.Fprintf(w, "\t%s %s `hessian:%q`\n", fn, typ, f.Name)
+	}
+	fmt.Fprintf(w, "}\n\n")
+	return nil
+}
+
+// fields fields of the class with fields of known super classes first
+func (e *emitter) fields(c *javaClass, seen map[string]bool) ([]javaField, error) {
+	if seen[c.Name] {
+		return nil, fmt.Errorf("cyclic inheritance of %s", c.Name)
+	}
+	seen[c.Name] = true
+
+	var ans []javaField
+	if len(c.Super) > 0 {
+		if super, ok := e.classes[e.resolve(c, c.Super)]; ok && !super.Enum {
+			fields, err := e.fields(super, seen)
+			if err != nil {
+				return nil, err
+			}
+			// fields of super class are resolved in its own scope
+			ans = append(ans, fields...)
+		}
+	}
+	for _, f := range c.Fields {
+		f.Type = e.resolveType(c, f.Type)
+		ans = append(ans, f)
+	}
+	return ans, nil
+}
+
+// resolveType resolve all type names to full names in the scope of class c
+func (e *emitter) resolveType(c *javaClass, t javaType) javaType {
+	if c.resolved {
+		return t
+	}
+	ans := javaType{Name: e.resolve(c, t.Name), Dims: t.Dims}
+	for _, a := range t.Args {
+		ans.Args = append(ans.Args, e.resolveType(c, a))
+	}
+	return ans
+}
+
+// resolve resolve a type name as written in class c to full name
+func (e *emitter) resolve(c *javaClass, name string) string {
+	if c.resolved || len(name) == 0 {
+		return name
+	}
+	if _, ok := primitives[name]; ok {
+		return name
+	}
+	for _, v := range c.Vars {
+		if v == name {
+			return "?"
+		}
+	}
+
+	first, rest := name, ""
+	if i := strings.Index(name, "."); i >= 0 {
+		first, rest = name[:i], strings.Replace(name[i:], ".", "$", -1)
+	}
+
+	// nested class of the class or its outer classes
+	for outer := c.Name; len(outer) > 0; {
+		if _, ok := e.classes[outer+"$"+first+rest]; ok {
+			return outer + "$" + first + rest
+		}
+		i := strings.LastIndex(outer, "$")
+		if i < 0 {
+			break
+		}
+		outer = outer[:i]
+	}
+
+	// imports
+	if full, ok := c.Imports[first]; ok {
+		return full + rest
+	}
+
+	// same package
+	candidate := first + rest
+	if len(c.pkg()) > 0 {
+		candidate = c.pkg() + "." + first + rest
+	}
+	if _, ok := e.classes[candidate]; ok {
+		return candidate
+	}
+
+	// full name as written
+	if _, ok := e.classes[name]; ok || strings.Contains(name, ".") {
+		return name
+	}
+
+	// wildcard imports and implicit packages
+	for _, p := range append(append([]string(nil), c.Wildcards...), implicit...) {
+		full := p + "." + first + rest
+		if _, ok := e.classes[full]; ok || known(full) {
+			return full
+		}
+	}
+	return name
+}
+
+// known true if full is a java library type mapped to a go type
+func known(full string) bool {
+	_, ok := primitives[full]
+	return ok || dates[full] || len(javaClasses[full]) > 0 || lists[full] || maps[full] || full == "java.lang.Object"
+}
+
+// goType map resolved java type to go type, top is true for struct fields
+func (e *emitter) goType(c *javaClass, t javaType, top bool) (string, error) {
+	if t.Dims > 0 {
+		if t.Name == "byte" && t.Dims == 1 {
+			return "[]byte", nil
+		}
+		elem, err := e.goType(c, javaType{Name: t.Name, Args: t.Args, Dims: t.Dims - 1}, false)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	}
+
+	if p, ok := primitives[t.Name]; ok {
+		if p.boxed && top {
+			return "*" + p.goType, nil
+		}
+		return p.goType, nil
+	}
+
+	if dates[t.Name] {
+		e.usesTime = true
+		return "time.Time", nil
+	}
+
+	if typ, ok := javaClasses[t.Name]; ok {
+		e.usesBig = e.usesBig || t.Name == "java.math.BigInteger"
+		if top && !strings.HasPrefix(typ, "*") {
+			return "*" + typ, nil
+		}
+		return typ, nil
+	}
+
+	if lists[t.Name] {
+		elem := "interface{}"
+		if len(t.Args) == 1 {
+			var err error
+			if elem, err = e.goType(c, t.Args[0], false); err != nil {
+				return "", err
+			}
+		}
+		return "[]" + elem, nil
+	}
+
+	if maps[t.Name] {
+		key, val := "interface{}", "interface{}"
+		if len(t.Args) == 2 {
+			var err error
+			if key, err = e.goType(c, t.Args[0], false); err != nil {
+				return "", err
+			}
+			if val, err = e.goType(c, t.Args[1], false); err != nil {
+				return "", err
+			}
+		}
+		// go map key must be comparable
+		if strings.HasPrefix(key, "[]") || strings.HasPrefix(key, "map[") {
+			key = "interface{}"
+		}
+		return "map[" + key + "]" + val, nil
+	}
+
+	if name, ok := e.names[t.Name]; ok {
+		return "*" + name, nil
+	}
+
+	return "interface{}", nil
+}
+
+// register write RegisterTypes func of all generated types, enums are registered to
+// hessian.DefaultClassRegistry
+func (e *emitter) register(w *bytes.Buffer, classes []*javaClass) {
+	fmt.Fprintf(w, "// RegisterTypes register all generated types to the proxy\n")
+	fmt.Fprintf(w, "func RegisterTypes(proxy *hessian.Proxy) error {\n")
+	for _, c := range classes {
+		if !c.Enum || len(c.Constants) == 0 {
+			continue
+		}
+		name := e.names[c.Name]
+		constants := make([]string, len(c.Constants))
+		for k, v := range c.Constants {
+			constants[k] = name + exported(v)
+		}
+		fmt.Fprintf(w, "\tif err := hessian.RegisterEnum(%q, %s); err != nil {\n\t\treturn err\n\t}\n", c.Name, strings.Join(constants, ", "))
+	}
+	fmt.Fprintf(w, "\tfor _, t := range []reflect.Type{\n")
+	for _, c := range classes {
+		if !c.Enum {
+			fmt.Fprintf(w, "\t\treflect.TypeOf(&%s{}),\n", e.names[c.Name])
+		}
+	}
+	fmt.Fprintf(w, "\t} {\n\t\tif err := proxy.RegisterType(t); err != nil {\n\t\t\treturn err\n\t\t}\n\t}\n")
+	fmt.Fprintf(w, "\treturn nil\n}\n")
+}
+
+// exported upper the first letter of name, and drop characters invalid in go identifier
+func exported(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			continue
+		}
+		if sb.Len() == 0 {
+			r = unicode.ToUpper(r)
+		}
+		sb.WriteRune(r)
+	}
+	if sb.Len() == 0 || !unicode.IsLetter([]rune(sb.String())[0]) {
+		return "X" + sb.String()
+	}
+	return sb.String()
+}
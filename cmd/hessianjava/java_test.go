@@ -0,0 +1,161 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestEmit(t *testing.T) {
+	base := `package lab.ggw.dto;
+
+import java.util.Date;
+
+public abstract class Base {
+	private Long id;
+	private Date created;
+}
+`
+	user := `package lab.ggw.dto;
+
+import java.math.*;
+import java.util.*;
+import lab.ggw.other.Address;
+
+public class User<T> extends Base {
+	private String name;
+	private int age;
+	private Integer score;
+	private boolean active;
+	private double[] weights;
+	private byte[] avatar;
+	private List<User> friends;
+	private Map<String, List<Color>> tags;
+	private Set<List<String>> sets;
+	private Map<List<String>, String> byList;
+	private Address address;
+	private T payload;
+	private Object any;
+	private Inner inner;
+	private BigDecimal balance;
+	private BigInteger big;
+	private UUID uuid;
+	private Locale locale;
+	private Currency currency;
+	private List<UUID> ids;
+
+	public static class Inner {
+		private java.sql.Timestamp at;
+	}
+}
+`
+	color := `package lab.ggw.dto;
+
+public enum Color { RED, GREEN }
+`
+	other := `package lab.ggw.other;
+
+public class User {
+	private String name;
+}
+`
+
+	var classes []*javaClass
+	for _, src := range []string{base, user, color, other} {
+		cs, err := parseSource(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		classes = append(classes, cs...)
+	}
+
+	got, err := emit("model", classes)
+	if err != nil {
+		t.Fatalf("emit() error = %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "out.go", got, 0); err != nil {
+		t.Errorf("emit() output is invalid: %v\n%s", err, got)
+	}
+
+	// compare without gofmt alignment
+	flat := strings.Join(strings.Fields(string(got)), " ")
+	for _, c := range []string{
+		"// Code generated by hessianjava. DO NOT EDIT.",
+		"package model",
+		`"math/big"`,
+		`"time"`,
+		`hessian "github.com/ggwhite/go-hessian"`,
+		"type Base struct",
+		"type LabGgwDtoUser struct",
+		"type LabGgwOtherUser struct",
+		"type UserInner struct",
+		`hessian.Package ` + "`" + `hessian:"lab.ggw.dto.User$Inner"` + "`",
+		"Id *int64",
+		"Created time.Time",
+		"Name string",
+		"Age int32",
+		"Score *int32",
+		"Active bool",
+		"Weights []float64",
+		"Avatar []byte",
+		"Friends []*LabGgwDtoUser",
+		"Tags map[string][]*Color",
+		"Sets [][]string",
+		"ByList map[interface{}]string",
+		"Address interface{}",
+		"Payload interface{}",
+		"Any interface{}",
+		"Inner *UserInner",
+		"At time.Time",
+		"Balance *hessian.Decimal",
+		"Big *big.Int",
+		"Uuid *hessian.UUID",
+		"Locale *hessian.Locale",
+		"Currency *hessian.Currency",
+		"Ids []hessian.UUID",
+		"type Color string",
+		`ColorRED Color = "RED"`,
+		`ColorGREEN Color = "GREEN"`,
+		"func RegisterTypes(proxy *hessian.Proxy) error",
+		`hessian.RegisterEnum("lab.ggw.dto.Color", ColorRED, ColorGREEN)`,
+		"reflect.TypeOf(&Base{}),",
+	} {
+		if !strings.Contains(flat, c) {
+			t.Errorf("emit() output without %q\n%s", c, got)
+		}
+	}
+
+	if strings.Contains(flat, "reflect.TypeOf(&Color{})") {
+		t.Errorf("emit() enum registered as struct\n%s", got)
+	}
+
+	// fields of super class come first
+	if strings.Index(flat, "Id *int64") > strings.Index(flat, "Name string") {
+		t.Errorf("emit() super class fields are not first\n%s", got)
+	}
+}
+
+func TestEmit_Duplicate(t *testing.T) {
+	classes := []*javaClass{
+		{Name: "a.User"},
+		{Name: "a.User"},
+	}
+	if _, err := emit("model", classes); err == nil {
+		t.Errorf("emit() error = %v, wantErr %v", err, true)
+	}
+}
+
+func TestExported(t *testing.T) {
+	tests := map[string]string{
+		"name":   "Name",
+		"$name":  "Name",
+		"_id":    "X_id",
+		"9lives": "X9lives",
+	}
+	for in, want := range tests {
+		if got := exported(in); got != want {
+			t.Errorf("exported(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Command hessianjava generate Go structs with hessian.Package tags from Java source files,
+// compiled class files and jar archives.
+//
+// Usage:
+//
+//	hessianjava -pkg model -output model/model_hessian.go src/main/java/lab/ggw/dto
+//	hessianjava -pkg model -output model/model_hessian.go vendor-api.jar
+//
+// Each java class become a struct with the hessian.Package field and hessian field tags,
+// static and transient fields are skipped the same way as java hessian serialization,
+// fields of super classes in the input are flattened. java.util collections become slices
+// and maps, boxed types become pointers, and unknown classes become interface{}.
+// A RegisterTypes(*hessian.Proxy) func registers all generated types.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+var (
+	pkgName = flag.String("pkg", "model", "package name of generated code")
+	output  = flag.String("output", "", "output file name; default stdout")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage of hessianjava:\n")
+	fmt.Fprintf(os.Stderr, "\thessianjava [flags] .java, .class, .jar files or directories...\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("hessianjava: ")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	classes, err := load(flag.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := emit(*pkgName, classes)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(*output) == 0 {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := ioutil.WriteFile(*output, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// load parse classes from .java, .class and .jar files, and such files in directories
+func load(args []string) ([]*javaClass, error) {
+	var classes []*javaClass
+
+	for _, arg := range args {
+		err := filepath.Walk(arg, func(name string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			ext := filepath.Ext(name)
+			if info.IsDir() || (ext != ".java" && ext != ".class" && ext != ".jar") {
+				if name == arg && !info.IsDir() {
+					return fmt.Errorf("%s: unknown file type", name)
+				}
+				return nil
+			}
+
+			p, err := ioutil.ReadFile(name)
+			if err != nil {
+				return err
+			}
+
+			var cs []*javaClass
+			switch ext {
+			case ".java":
+				cs, err = parseSource(string(p))
+			case ".class":
+				var c *javaClass
+				if c, err = parseClass(p); c != nil {
+					cs = []*javaClass{c}
+				}
+			case ".jar":
+				cs, err = parseJarBytes(p)
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+			classes = append(classes, cs...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return classes, nil
+}
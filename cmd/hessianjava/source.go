@@ -0,0 +1,570 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// modifiers java modifiers, skipped before declarations
+var modifiers = map[string]bool{
+	"public": true, "protected": true, "private": true, "static": true, "final": true,
+	"abstract": true, "transient": true, "volatile": true, "synchronized": true,
+	"native": true, "strictfp": true, "default": true, "sealed": true, "non-sealed": true,
+}
+
+// tokenize split java source to identifiers, literals and symbols, without comments,
+// annotations, string and char literals (replaced by token "\"\"")
+func tokenize(src string) ([]string, error) {
+	var tokens []string
+	rs := []rune(src)
+
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '/':
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '*':
+			end := strings.Index(string(rs[i+2:]), "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += 2 + len([]rune(string(rs[i+2:])[:end])) + 2
+		case r == '"' || r == '\'':
+			// text block """ ... """
+			if r == '"' && i+2 < len(rs) && rs[i+1] == '"' && rs[i+2] == '"' {
+				end := strings.Index(string(rs[i+3:]), `"""`)
+				if end < 0 {
+					return nil, fmt.Errorf("unterminated text block")
+				}
+				i += 3 + len([]rune(string(rs[i+3:])[:end])) + 3
+			} else {
+				j := i + 1
+				for ; j < len(rs) && rs[j] != r; j++ {
+					if rs[j] == '\\' {
+						j++
+					}
+				}
+				if j >= len(rs) {
+					return nil, fmt.Errorf("unterminated literal")
+				}
+				i = j + 1
+			}
+			tokens = append(tokens, `""`)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$':
+			j := i
+			for j < len(rs) && (unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j]) || rs[j] == '_' || rs[j] == '$') {
+				j++
+			}
+			tokens = append(tokens, string(rs[i:j]))
+			i = j
+		case r == '.' && i+2 < len(rs) && rs[i+1] == '.' && rs[i+2] == '.':
+			tokens = append(tokens, "...")
+			i += 3
+		default:
+			tokens = append(tokens, string(r))
+			i++
+		}
+	}
+
+	return stripAnnotations(tokens), nil
+}
+
+// stripAnnotations remove annotations like @Foo, @a.b.Foo(x = 1), but keep @interface
+func stripAnnotations(tokens []string) []string {
+	var ans []string
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i] != "@" || i+1 >= len(tokens) || tokens[i+1] == "interface" {
+			ans = append(ans, tokens[i])
+			continue
+		}
+		i++
+		for i+2 < len(tokens) && tokens[i+1] == "." {
+			i += 2
+		}
+		if i+1 < len(tokens) && tokens[i+1] == "(" {
+			depth := 0
+			for i++; i < len(tokens); i++ {
+				if tokens[i] == "(" {
+					depth++
+				} else if tokens[i] == ")" {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+		}
+	}
+	return ans
+}
+
+// sourceParser parser of a java compilation unit
+type sourceParser struct {
+	tokens    []string
+	pos       int
+	pkg       string
+	imports   map[string]string
+	wildcards []string
+	classes   []*javaClass
+}
+
+// parseSource parse classes and enums declared in java source
+func parseSource(src string) ([]*javaClass, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &sourceParser{
+		tokens:  tokens,
+		imports: make(map[string]string),
+	}
+	if err := p.compilationUnit(); err != nil {
+		return nil, fmt.Errorf("%v near token %d %q", err, p.pos, p.peek())
+	}
+	return p.classes, nil
+}
+
+func (p *sourceParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *sourceParser) peekAt(n int) string {
+	if p.pos+n < len(p.tokens) {
+		return p.tokens[p.pos+n]
+	}
+	return ""
+}
+
+func (p *sourceParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *sourceParser) expect(t string) error {
+	if got := p.next(); got != t {
+		return fmt.Errorf("expect %q but %q", t, got)
+	}
+	return nil
+}
+
+func (p *sourceParser) eof() bool {
+	return p.pos >= len(p.tokens)
+}
+
+// qualifiedName a.b.C
+func (p *sourceParser) qualifiedName() string {
+	name := p.next()
+	for p.peek() == "." && isIdent(p.peekAt(1)) {
+		p.pos++
+		name += "." + p.next()
+	}
+	return name
+}
+
+// skipBalanced skip from open token to its close token
+func (p *sourceParser) skipBalanced(open, close string) error {
+	depth := 0
+	for !p.eof() {
+		switch p.next() {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("unbalanced %s%s", open, close)
+}
+
+func (p *sourceParser) compilationUnit() error {
+	for !p.eof() {
+		switch p.peek() {
+		case ";":
+			p.pos++
+		case "package":
+			p.pos++
+			p.pkg = p.qualifiedName()
+			if err := p.expect(";"); err != nil {
+				return err
+			}
+		case "import":
+			p.pos++
+			static := p.peek() == "static"
+			if static {
+				p.pos++
+			}
+			name := p.qualifiedName()
+			wildcard := p.peek() == "."
+			if wildcard {
+				p.pos += 2
+			}
+			if !static && !wildcard {
+				p.imports[name[strings.LastIndex(name, ".")+1:]] = name
+			} else if !static {
+				p.wildcards = append(p.wildcards, name)
+			}
+			if err := p.expect(";"); err != nil {
+				return err
+			}
+		default:
+			if err := p.typeDecl(""); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// typeDecl parse class, interface, enum, record or annotation declaration, outer is binary name of outer class
+func (p *sourceParser) typeDecl(outer string) error {
+	for modifiers[p.peek()] {
+		p.pos++
+	}
+
+	kind := p.next()
+	if kind == "@" {
+		kind = "@" + p.next()
+	}
+	switch kind {
+	default:
+		return fmt.Errorf("unexpected %q", kind)
+	case "class", "interface", "enum", "record", "@interface":
+	}
+
+	name := p.next()
+	if !isIdent(name) {
+		return fmt.Errorf("invalid type name %q", name)
+	}
+	full := name
+	if len(outer) > 0 {
+		full = outer + "$" + name
+	} else if len(p.pkg) > 0 {
+		full = p.pkg + "." + name
+	}
+
+	c := &javaClass{
+		Name:      full,
+		Imports:   p.imports,
+		Wildcards: p.wildcards,
+		Enum:      kind == "enum",
+	}
+
+	if p.peek() == "<" {
+		vars, err := p.typeParams()
+		if err != nil {
+			return err
+		}
+		c.Vars = vars
+	}
+	if kind == "record" && p.peek() == "(" {
+		if err := p.skipBalanced("(", ")"); err != nil {
+			return err
+		}
+	}
+
+	for p.peek() != "{" {
+		if p.eof() {
+			return fmt.Errorf("unexpected end of %s", full)
+		}
+		switch p.next() {
+		case "extends":
+			t, err := p.javaType()
+			if err != nil {
+				return err
+			}
+			if kind == "class" {
+				c.Super = t.Name
+			}
+		case "<":
+			p.pos--
+			if err := p.skipBalanced("<", ">"); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := p.body(c, kind == "enum"); err != nil {
+		return err
+	}
+
+	// only classes and enums are serializable data types
+	if kind == "class" || kind == "enum" {
+		p.classes = append(p.classes, c)
+	}
+	return nil
+}
+
+// typeParams parse <T, K extends Foo<K>>, return type variable names
+func (p *sourceParser) typeParams() ([]string, error) {
+	var vars []string
+	start := p.pos
+	if err := p.skipBalanced("<", ">"); err != nil {
+		return nil, err
+	}
+	depth := 0
+	for j := start; j < p.pos; j++ {
+		switch t := p.tokens[j]; t {
+		case "<":
+			depth++
+			if depth == 1 && isIdent(p.tokens[j+1]) {
+				vars = append(vars, p.tokens[j+1])
+			}
+		case ">":
+			depth--
+		case ",":
+			if depth == 1 && isIdent(p.tokens[j+1]) {
+				vars = append(vars, p.tokens[j+1])
+			}
+		}
+	}
+	return vars, nil
+}
+
+// body parse class body, collect enum constants and fields
+func (p *sourceParser) body(c *javaClass, enum bool) error {
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+
+	if enum {
+		for p.peek() != ";" && p.peek() != "}" {
+			name := p.next()
+			if !isIdent(name) {
+				return fmt.Errorf("invalid enum constant %q", name)
+			}
+			c.Constants = append(c.Constants, name)
+			if p.peek() == "(" {
+				if err := p.skipBalanced("(", ")"); err != nil {
+					return err
+				}
+			}
+			if p.peek() == "{" {
+				if err := p.skipBalanced("{", "}"); err != nil {
+					return err
+				}
+			}
+			if p.peek() == "," {
+				p.pos++
+			}
+		}
+		if p.peek() == ";" {
+			p.pos++
+		}
+	}
+
+	for p.peek() != "}" {
+		if p.eof() {
+			return fmt.Errorf("unexpected end of %s", c.Name)
+		}
+		if err := p.member(c); err != nil {
+			return err
+		}
+	}
+	p.pos++
+	return nil
+}
+
+// member parse a member of class body
+func (p *sourceParser) member(c *javaClass) error {
+	var static, transient bool
+	for modifiers[p.peek()] {
+		switch p.next() {
+		case "static":
+			static = true
+		case "transient":
+			transient = true
+		}
+	}
+
+	switch p.peek() {
+	case ";":
+		p.pos++
+		return nil
+	case "{":
+		// initializer
+		return p.skipBalanced("{", "}")
+	case "class", "interface", "enum", "record", "@":
+		if p.peek() == "record" && !isIdent(p.peekAt(1)) {
+			break
+		}
+		return p.typeDecl(c.Name)
+	case "<":
+		// generic method
+		if err := p.skipBalanced("<", ">"); err != nil {
+			return err
+		}
+		return p.method()
+	}
+
+	t, err := p.javaType()
+	if err != nil {
+		return err
+	}
+
+	// constructor
+	if p.peek() == "(" {
+		return p.method()
+	}
+
+	for {
+		name := p.next()
+		if !isIdent(name) {
+			return fmt.Errorf("invalid member name %q", name)
+		}
+		if p.peek() == "(" {
+			return p.method()
+		}
+
+		ft := t
+		for p.peek() == "[" {
+			p.pos += 2
+			ft.Dims++
+		}
+		if !static && !transient {
+			c.Fields = append(c.Fields, javaField{Name: name, Type: ft})
+		}
+
+		if p.peek() == "=" {
+			p.pos++
+			if err := p.initializer(); err != nil {
+				return err
+			}
+		}
+
+		switch p.next() {
+		case ";":
+			return nil
+		case ",":
+		default:
+			return fmt.Errorf("invalid field declaration of %s", name)
+		}
+	}
+}
+
+// initializer skip a field initializer until the ',' of next declarator or ';'
+func (p *sourceParser) initializer() error {
+	depth := 0
+	for !p.eof() {
+		switch p.peek() {
+		case "(", "{", "[":
+			depth++
+		case ")", "}", "]":
+			depth--
+		case ";":
+			if depth == 0 {
+				return nil
+			}
+		case ",":
+			// next declarator is `name =`, `name ,`, `name ;` or `name [`, otherwise the comma
+			// belongs to the initializer, ex: new HashMap<String, Integer>()
+			if next := p.peekAt(2); depth == 0 && isIdent(p.peekAt(1)) && (next == "=" || next == "," || next == ";" || next == "[") {
+				return nil
+			}
+		}
+		p.pos++
+	}
+	return fmt.Errorf("unexpected end of initializer")
+}
+
+// method skip parameters, throws clause and body of method or constructor
+func (p *sourceParser) method() error {
+	for p.peek() != "(" {
+		if p.eof() {
+			return fmt.Errorf("unexpected end of method")
+		}
+		p.pos++
+	}
+	if err := p.skipBalanced("(", ")"); err != nil {
+		return err
+	}
+	for !p.eof() {
+		switch p.peek() {
+		case ";":
+			p.pos++
+			return nil
+		case "{":
+			return p.skipBalanced("{", "}")
+		}
+		p.pos++
+	}
+	return fmt.Errorf("unexpected end of method")
+}
+
+// javaType parse type like a.b.C<D, ? extends E>[]
+func (p *sourceParser) javaType() (javaType, error) {
+	var t javaType
+
+	if p.peek() == "?" {
+		p.pos++
+		if p.peek() == "extends" {
+			p.pos++
+			return p.javaType()
+		}
+		if p.peek() == "super" {
+			p.pos++
+			if _, err := p.javaType(); err != nil {
+				return t, err
+			}
+		}
+		return javaType{Name: "java.lang.Object"}, nil
+	}
+
+	if !isIdent(p.peek()) {
+		return t, fmt.Errorf("invalid type %q", p.peek())
+	}
+	t.Name = p.qualifiedName()
+
+	if p.peek() == "<" {
+		p.pos++
+		for p.peek() != ">" {
+			if p.eof() {
+				return t, fmt.Errorf("unexpected end of type arguments")
+			}
+			a, err := p.javaType()
+			if err != nil {
+				return t, err
+			}
+			t.Args = append(t.Args, a)
+			if p.peek() == "," {
+				p.pos++
+			}
+		}
+		p.pos++
+		// Outer<A>.Inner
+		for p.peek() == "." && isIdent(p.peekAt(1)) {
+			p.pos++
+			t.Name += "." + p.next()
+			t.Args = nil
+		}
+	}
+
+	for p.peek() == "[" && p.peekAt(1) == "]" {
+		p.pos += 2
+		t.Dims++
+	}
+	if p.peek() == "..." {
+		p.pos++
+		t.Dims++
+	}
+	return t, nil
+}
+
+// isIdent check token is a java identifier
+func isIdent(t string) bool {
+	if len(t) == 0 || t == `""` {
+		return false
+	}
+	r := []rune(t)[0]
+	return unicode.IsLetter(r) || r == '_' || r == '$'
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    []*javaClass
+		wantErr bool
+	}{
+		{
+			name: "class",
+			src: `package lab.ggw.dto;
+
+import java.util.List;
+import java.util.Map;
+import java.io.*;
+import static java.lang.Math.max;
+
+/** a user */
+@Data
+public class User<T> extends Base implements Serializable, Comparable<User<T>> {
+	private static final long serialVersionUID = 1L;
+	private transient String cache;
+	// name of user
+	@JsonProperty("n")
+	private String name = "a;b", nick;
+	protected int age, scores[];
+	List<Map<String, List<Integer>>> groups = new ArrayList<>();
+	Map<String, ? extends Base> extra = new HashMap<String, Base>(), other;
+	T payload;
+	byte[] avatar;
+
+	public User() { this.name = "{"; }
+
+	public <R> R convert(Function<User, R> f) { return f.apply(this); }
+
+	public String getName() { return name; }
+
+	static { init(); }
+
+	public static class Inner {
+		private long id;
+	}
+
+	interface Visitor { void visit(User u); }
+}
+`,
+			want: []*javaClass{
+				{
+					Name:      "lab.ggw.dto.User$Inner",
+					Imports:   map[string]string{"List": "java.util.List", "Map": "java.util.Map"},
+					Wildcards: []string{"java.io"},
+					Fields: []javaField{
+						{Name: "id", Type: javaType{Name: "long"}},
+					},
+				},
+				{
+					Name:      "lab.ggw.dto.User",
+					Super:     "Base",
+					Imports:   map[string]string{"List": "java.util.List", "Map": "java.util.Map"},
+					Wildcards: []string{"java.io"},
+					Vars:      []string{"T"},
+					Fields: []javaField{
+						{Name: "name", Type: javaType{Name: "String"}},
+						{Name: "nick", Type: javaType{Name: "String"}},
+						{Name: "age", Type: javaType{Name: "int"}},
+						{Name: "scores", Type: javaType{Name: "int", Dims: 1}},
+						{Name: "groups", Type: javaType{Name: "List", Args: []javaType{
+							{Name: "Map", Args: []javaType{
+								{Name: "String"},
+								{Name: "List", Args: []javaType{{Name: "Integer"}}},
+							}},
+						}}},
+						{Name: "extra", Type: javaType{Name: "Map", Args: []javaType{{Name: "String"}, {Name: "Base"}}}},
+						{Name: "other", Type: javaType{Name: "Map", Args: []javaType{{Name: "String"}, {Name: "Base"}}}},
+						{Name: "payload", Type: javaType{Name: "T"}},
+						{Name: "avatar", Type: javaType{Name: "byte", Dims: 1}},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "enum",
+			src: `package lab.ggw.dto;
+
+public enum Color {
+	RED("r") {
+		@Override String code() { return "R"; }
+	},
+	GREEN("g"),
+	BLUE;
+
+	private final String code;
+
+	Color(String code) { this.code = code; }
+}
+`,
+			want: []*javaClass{
+				{
+					Name:      "lab.ggw.dto.Color",
+					Imports:   map[string]string{},
+					Enum:      true,
+					Constants: []string{"RED", "GREEN", "BLUE"},
+					Fields: []javaField{
+						{Name: "code", Type: javaType{Name: "String"}},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "unterminated",
+			src:     "package a; class A { String s = \"abc; }",
+			wantErr: true,
+		},
+		{
+			name:    "unbalanced",
+			src:     "package a; class A { void f() { }",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSource(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseSource() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSource() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package hessian
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+type compression int
+
+// Request body compressions
+const (
+	CompressNone compression = iota
+	CompressGzip
+	CompressDeflate
+)
+
+// contentEncoding return Content-Encoding value of the compression
+func (c compression) contentEncoding() string {
+	switch c {
+	case CompressGzip:
+		return "gzip"
+	case CompressDeflate:
+		return "deflate"
+	}
+	return ""
+}
+
+// compress compress body by given compression
+func compress(c compression, body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	var err error
+
+	switch c {
+	default:
+		return nil, fmt.Errorf("unknown compression %d", c)
+	case CompressNone:
+		return body, nil
+	case CompressGzip:
+		w = gzip.NewWriter(&buf)
+	case CompressDeflate:
+		// HTTP deflate is zlib format (RFC 1950)
+		w, err = zlib.NewWriterLevel(&buf, zlib.DefaultCompression)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress wrap r by given Content-Encoding
+func decompress(encoding string, r io.Reader) (io.ReadCloser, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	default:
+		return nil, fmt.Errorf("unsupported Content-Encoding %s", encoding)
+	case "", "identity":
+		return ioutil.NopCloser(r), nil
+	case "gzip", "x-gzip":
+		return gzip.NewReader(r)
+	case "deflate":
+		// some servers send raw deflate (RFC 1951) instead of zlib, check zlib header first
+		br := bufio.NewReader(r)
+		h, err := br.Peek(2)
+		if err == io.EOF {
+			return ioutil.NopCloser(br), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if h[0]&0x0f == 8 && (uint16(h[0])<<8|uint16(h[1]))%31 == 0 {
+			return zlib.NewReader(br)
+		}
+		return flate.NewReader(br), nil
+	}
+}
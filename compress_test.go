@@ -0,0 +1,163 @@
+package hessian
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestCompress(t *testing.T) {
+	body := []byte{'c', 0x01, 0x00, 'm', 0x00, 0x03, 's', 't', 'r', 'z'}
+	tests := []struct {
+		name     string
+		c        compression
+		encoding string
+		wantErr  bool
+	}{
+		{
+			name:     "None",
+			c:        CompressNone,
+			encoding: "",
+			wantErr:  false,
+		},
+		{
+			name:     "Gzip",
+			c:        CompressGzip,
+			encoding: "gzip",
+			wantErr:  false,
+		},
+		{
+			name:     "Deflate",
+			c:        CompressDeflate,
+			encoding: "deflate",
+			wantErr:  false,
+		},
+		{
+			name:    "Unknown",
+			c:       compression(123),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := compress(tt.c, body)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("compress() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if enc := tt.c.contentEncoding(); enc != tt.encoding {
+				t.Errorf("compression.contentEncoding() = %v, want %v", enc, tt.encoding)
+			}
+			r, err := decompress(tt.encoding, bytes.NewReader(got))
+			if err != nil {
+				t.Errorf("decompress() error = %v", err)
+				return
+			}
+			b, _ := ioutil.ReadAll(r)
+			if !reflect.DeepEqual(b, body) {
+				t.Errorf("decompress() = %v, want %v", b, body)
+			}
+		})
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	body := []byte{'r', 0x01, 0x00, 'T', 'z'}
+
+	var raw bytes.Buffer
+	fw, _ := flate.NewWriter(&raw, flate.DefaultCompression)
+	fw.Write(body)
+	fw.Close()
+
+	tests := []struct {
+		name     string
+		encoding string
+		data     []byte
+		wantErr  bool
+	}{
+		{
+			name:     "Identity",
+			encoding: "identity",
+			data:     body,
+			wantErr:  false,
+		},
+		{
+			name:     "Raw Deflate",
+			encoding: "deflate",
+			data:     raw.Bytes(),
+			wantErr:  false,
+		},
+		{
+			name:     "Unsupported",
+			encoding: "br",
+			data:     body,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := decompress(tt.encoding, bytes.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decompress() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			b, _ := ioutil.ReadAll(r)
+			if !reflect.DeepEqual(b, body) {
+				t.Errorf("decompress() = %v, want %v", b, body)
+			}
+		})
+	}
+}
+
+func TestProxy_Invoke_Compression(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "gzip" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		req, _ := ioutil.ReadAll(zr)
+		if !bytes.HasPrefix(req, []byte{'c', 0x01, 0x00}) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		zw.Write([]byte{'r', 0x01, 0x00, 'S', 0x00, 0x02, 'o', 'k', 'z'})
+		zw.Close()
+	}))
+	defer srv.Close()
+
+	proxy, err := NewProxy(&ProxyConfig{
+		Version:     V1,
+		URL:         srv.URL,
+		Compression: CompressGzip,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := proxy.Invoke("str")
+	if err != nil {
+		t.Errorf("Proxy.Invoke() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, []interface{}{"ok"}) {
+		t.Errorf("Proxy.Invoke() = %v, want %v", got, []interface{}{"ok"})
+	}
+}
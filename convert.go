@@ -0,0 +1,169 @@
+package hessian
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// convertValue convert a decoded value (from Deserializer) to given go type.
+//
+// Numbers are converted between kinds with overflow check, []interface{} to typed slice or array,
+// map[interface{}]interface{} to typed map or struct (by hessian tag), and struct to ptr or ptr to struct.
+// A value converted to RawMessage or Unmarshaler is encoded again.
+func convertValue(v interface{}, t reflect.Type) (reflect.Value, error) {
+	if t == rawMessageType {
+		return rawMessageOf(v)
+	}
+	if v == nil {
+		return reflect.Zero(t), nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Type().AssignableTo(t) {
+		return rv, nil
+	}
+
+	if t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(unmarshalerType) {
+		raw, err := rawMessageOf(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return unmarshalHessian(raw.Bytes(), t, defaultCodec)
+	}
+
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return reflect.Zero(t), nil
+		}
+		if t.Kind() != reflect.Ptr {
+			return convertValue(rv.Elem().Interface(), t)
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		e, err := convertValue(v, t.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		p := reflect.New(t.Elem())
+		p.Elem().Set(e)
+		return p, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := reflect.New(t).Elem()
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if n.OverflowInt(rv.Int()) {
+				return reflect.Value{}, fmt.Errorf("value %v overflows %s", v, t)
+			}
+			n.SetInt(rv.Int())
+			return n, nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if rv.Uint() > 1<<63-1 || n.OverflowInt(int64(rv.Uint())) {
+				return reflect.Value{}, fmt.Errorf("value %v overflows %s", v, t)
+			}
+			n.SetInt(int64(rv.Uint()))
+			return n, nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n := reflect.New(t).Elem()
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if rv.Int() < 0 || n.OverflowUint(uint64(rv.Int())) {
+				return reflect.Value{}, fmt.Errorf("value %v overflows %s", v, t)
+			}
+			n.SetUint(uint64(rv.Int()))
+			return n, nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if n.OverflowUint(rv.Uint()) {
+				return reflect.Value{}, fmt.Errorf("value %v overflows %s", v, t)
+			}
+			n.SetUint(rv.Uint())
+			return n, nil
+		}
+	case reflect.Float32, reflect.Float64:
+		switch rv.Kind() {
+		case reflect.Float32, reflect.Float64:
+			n := reflect.New(t).Elem()
+			if f := rv.Float(); !math.IsInf(f, 0) && n.OverflowFloat(f) {
+				return reflect.Value{}, fmt.Errorf("value %v overflows %s", v, t)
+			}
+			return rv.Convert(t), nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return rv.Convert(t), nil
+		}
+	case reflect.String, reflect.Bool:
+		if rv.Kind() == t.Kind() {
+			return rv.Convert(t), nil
+		}
+	case reflect.Slice:
+		if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			s := reflect.MakeSlice(t, rv.Len(), rv.Len())
+			for j := 0; j < rv.Len(); j++ {
+				e, err := convertValue(rv.Index(j).Interface(), t.Elem())
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				s.Index(j).Set(e)
+			}
+			return s, nil
+		}
+	case reflect.Array:
+		if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			if rv.Len() != t.Len() {
+				return reflect.Value{}, fmt.Errorf("array length not equal %d != %d", rv.Len(), t.Len())
+			}
+			a := reflect.New(t).Elem()
+			for j := 0; j < rv.Len(); j++ {
+				e, err := convertValue(rv.Index(j).Interface(), t.Elem())
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				a.Index(j).Set(e)
+			}
+			return a, nil
+		}
+	case reflect.Map:
+		if rv.Kind() == reflect.Map {
+			m := reflect.MakeMapWithSize(t, rv.Len())
+			for _, key := range rv.MapKeys() {
+				k, err := convertValue(key.Interface(), t.Key())
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				e, err := convertValue(rv.MapIndex(key).Interface(), t.Elem())
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				m.SetMapIndex(k, e)
+			}
+			return m, nil
+		}
+	case reflect.Struct:
+		if rv.Kind() == reflect.Map && reflect.TypeOf("").AssignableTo(rv.Type().Key()) {
+			s := reflect.New(t).Elem()
+			for j, l := 0, t.NumField(); j < l; j++ {
+				if t.Field(j).PkgPath != "" || t.Field(j).Type == reflect.TypeOf(Package("")) {
+					continue
+				}
+				tag := t.Field(j).Tag.Get(tagName)
+				if len(tag) == 0 {
+					continue
+				}
+				val := rv.MapIndex(reflect.ValueOf(tag))
+				if !val.IsValid() {
+					continue
+				}
+				e, err := convertValue(val.Interface(), t.Field(j).Type)
+				if err != nil {
+					return reflect.Value{}, fmt.Errorf("%s.%s: %v", t, t.Field(j).Name, err)
+				}
+				s.Field(j).Set(e)
+			}
+			return s, nil
+		}
+	}
+
+	return reflect.Value{}, fmt.Errorf("can not convert %s to %s", rv.Type(), t)
+}
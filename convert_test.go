@@ -0,0 +1,153 @@
+package hessian
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestConvertValue(t *testing.T) {
+	type User struct {
+		Package `hessian:"lab.ggw.shs.service.User"`
+		Name    string   `hessian:"name"`
+		Age     int      `hessian:"age"`
+		Tags    []string `hessian:"tags"`
+		Father  *User    `hessian:"father"`
+	}
+	type args struct {
+		v interface{}
+		t reflect.Type
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name:    "nil",
+			args:    args{v: nil, t: reflect.TypeOf(&User{})},
+			want:    (*User)(nil),
+			wantErr: false,
+		},
+		{
+			name:    "int32 to int",
+			args:    args{v: int32(12), t: reflect.TypeOf(int(0))},
+			want:    int(12),
+			wantErr: false,
+		},
+		{
+			name:    "int64 to int8 overflow",
+			args:    args{v: int64(math.MaxInt64), t: reflect.TypeOf(int8(0))},
+			wantErr: true,
+		},
+		{
+			name:    "negative to uint",
+			args:    args{v: int32(-1), t: reflect.TypeOf(uint(0))},
+			wantErr: true,
+		},
+		{
+			name:    "int32 to float64",
+			args:    args{v: int32(1), t: reflect.TypeOf(float64(0))},
+			want:    float64(1),
+			wantErr: false,
+		},
+		{
+			name:    "int64 to uint32 overflow",
+			args:    args{v: int64(math.MaxUint32 + 1), t: reflect.TypeOf(uint32(0))},
+			wantErr: true,
+		},
+		{
+			name:    "float64 to float32",
+			args:    args{v: float64(1.5), t: reflect.TypeOf(float32(0))},
+			want:    float32(1.5),
+			wantErr: false,
+		},
+		{
+			name:    "float64 to float32 overflow",
+			args:    args{v: math.MaxFloat64, t: reflect.TypeOf(float32(0))},
+			wantErr: true,
+		},
+		{
+			name:    "float64 infinity to float32",
+			args:    args{v: math.Inf(1), t: reflect.TypeOf(float32(0))},
+			want:    float32(math.Inf(1)),
+			wantErr: false,
+		},
+		{
+			name:    "string to int",
+			args:    args{v: "1", t: reflect.TypeOf(int(0))},
+			wantErr: true,
+		},
+		{
+			name:    "slice",
+			args:    args{v: []interface{}{int32(1), int32(2)}, t: reflect.TypeOf([]int64{})},
+			want:    []int64{1, 2},
+			wantErr: false,
+		},
+		{
+			name:    "array",
+			args:    args{v: []interface{}{"a", "b"}, t: reflect.TypeOf([2]string{})},
+			want:    [2]string{"a", "b"},
+			wantErr: false,
+		},
+		{
+			name:    "array length",
+			args:    args{v: []interface{}{"a"}, t: reflect.TypeOf([2]string{})},
+			wantErr: true,
+		},
+		{
+			name:    "map",
+			args:    args{v: map[interface{}]interface{}{"a": int32(1)}, t: reflect.TypeOf(map[string]int{})},
+			want:    map[string]int{"a": 1},
+			wantErr: false,
+		},
+		{
+			name: "map to struct ptr",
+			args: args{
+				v: map[interface{}]interface{}{
+					"name":   "ggw",
+					"age":    int32(18),
+					"tags":   []interface{}{"a"},
+					"father": map[interface{}]interface{}{"name": "father"},
+				},
+				t: reflect.TypeOf(&User{}),
+			},
+			want:    &User{Name: "ggw", Age: 18, Tags: []string{"a"}, Father: &User{Name: "father"}},
+			wantErr: false,
+		},
+		{
+			name:    "struct ptr to struct",
+			args:    args{v: &User{Name: "ggw"}, t: reflect.TypeOf(User{})},
+			want:    User{Name: "ggw"},
+			wantErr: false,
+		},
+		{
+			name:    "struct to struct ptr",
+			args:    args{v: User{Name: "ggw"}, t: reflect.TypeOf(&User{})},
+			want:    &User{Name: "ggw"},
+			wantErr: false,
+		},
+		{
+			name:    "interface",
+			args:    args{v: "ggw", t: reflect.TypeOf((*interface{})(nil)).Elem()},
+			want:    "ggw",
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertValue(tt.args.v, tt.args.t)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("convertValue() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got.Interface(), tt.want) {
+				t.Errorf("convertValue() = %v, want %v", got.Interface(), tt.want)
+			}
+		})
+	}
+}
@@ -11,9 +11,11 @@ import (
 
 // DeserializerV1 input stream for hessian 1.0 response
 type DeserializerV1 struct {
-	version int
-	r       io.Reader
-	typeMap map[string]reflect.Type
+	version   int
+	r         io.Reader
+	typeMap   map[string]reflect.Type
+	keepTypes bool
+	classes   *ClassRegistry
 }
 
 // ReadAt Read object from given bytes and begin index.
@@ -34,105 +36,245 @@ func (i *DeserializerV1) ReadAt(p []byte, begin int) ([]interface{}, int, error)
 			if err != nil {
 				return nil, j, err
 			}
-			return nil, j, fmt.Errorf("Call Service Error, Exception: %s, Message: %s", args[1].(string), args[3].(string))
+			return nil, j, newFault(args)
 		case 'z':
 			return ans, j, nil
-		case 'T':
-			ans = append(ans, true)
-		case 'F':
-			ans = append(ans, false)
-		case 'N':
-			ans = append(ans, nil)
-		case 'B':
-			// B b16 b8 byte-value
-			var val []byte
-			val, j, err = i.ReadBytesAt(p, j+1)
+		case 'H':
+			// H b16 b8 header-name <value>, headers are skipped
+			_, j, err = i.ReadStringAt(p, j+1)
 			if err != nil {
 				return nil, j, err
 			}
-			ans = append(ans, val)
-		case 'S':
-			// S b16 b8 string-value
-			var val string
-			val, j, err = i.ReadStringAt(p, j+1)
+			_, j, err = i.ReadValueAt(p, j+1)
 			if err != nil {
 				return nil, j, err
 			}
-			ans = append(ans, val)
-		case 'I':
-			// I b32 b24 b16 b8
-			var val int32
-			val, j, err = i.ReadInt32At(p, j+1)
-			if err != nil {
-				return nil, j, err
+		case 'M', 'V':
+			if p[j+1] != 't' {
+				continue
 			}
-			ans = append(ans, val)
-		case 'L':
-			// L b64 b56 b48 b40 b32 b24 b16 b8
-			var val int64
-			val, j, err = i.ReadInt64At(p, j+1)
+			fallthrough
+		case 'T', 'F', 'N', 'B', 'S', 'I', 'L', 'D', 'd':
+			var val interface{}
+			val, j, err = i.ReadValueAt(p, j)
 			if err != nil {
 				return nil, j, err
 			}
 			ans = append(ans, val)
-		case 'D':
-			// D b64 b56 b48 b40 b32 b24 b16 b8
-			var val float64
-			val, j, err = i.ReadFloat64At(p, j+1)
+		}
+	}
+
+	return ans, j, nil
+}
+
+// ReadValueAt Read one object from given bytes and begin index, return the object and the index of its last byte.
+func (i *DeserializerV1) ReadValueAt(p []byte, begin int) (interface{}, int, error) {
+	var j = begin
+	var err error
+
+	if j >= len(p) {
+		return nil, j, fmt.Errorf("parse value: unexpected end")
+	}
+
+	switch p[j] {
+	default:
+		return nil, j, fmt.Errorf("parse value: unknown tag 0x%02x", p[j])
+	case 'T':
+		return true, j, nil
+	case 'F':
+		return false, j, nil
+	case 'N':
+		return nil, j, nil
+	case 'B':
+		// B b16 b8 byte-value
+		return i.ReadBytesAt(p, j+1)
+	case 'S':
+		// S b16 b8 string-value
+		return i.ReadStringAt(p, j+1)
+	case 'I':
+		// I b32 b24 b16 b8
+		return i.ReadInt32At(p, j+1)
+	case 'L':
+		// L b64 b56 b48 b40 b32 b24 b16 b8
+		return i.ReadInt64At(p, j+1)
+	case 'D':
+		// D b64 b56 b48 b40 b32 b24 b16 b8
+		return i.ReadFloat64At(p, j+1)
+	case 'd':
+		// d b64 b56 b48 b40 b32 b24 b16 b8
+		return i.ReadDateAt(p, j+1)
+	case 'M':
+		if j+1 >= len(p) || p[j+1] != 't' {
+			return nil, j, fmt.Errorf("parse value: expect 't' after 'M'")
+		}
+		j++
+		var pkg string
+		var m map[interface{}]interface{}
+		pkg, j, err = i.ReadStringAt(p, j+1)
+		if err != nil {
+			return nil, j, err
+		}
+
+		if i.keepTypes {
+			var tm TypedMap
+			tm, j, err = i.ReadTypedMapAt(p, j+1)
 			if err != nil {
 				return nil, j, err
 			}
-			ans = append(ans, val)
-		case 'd':
-			// d b64 b56 b48 b40 b32 b24 b16 b8
-			var val time.Time
-			val, j, err = i.ReadDateAt(p, j+1)
+			tm.Type = pkg
+			return tm, j, nil
+		}
+
+		if c, ok := i.classes.byClass(pkg); ok {
+			var tm TypedMap
+			tm, j, err = i.ReadTypedMapAt(p, j+1)
 			if err != nil {
 				return nil, j, err
 			}
-			ans = append(ans, val)
-		case 'M':
-			if p[j+1] == 't' {
-				j++
-				var pkg string
-				var m = make(map[interface{}]interface{})
-				pkg, j, err = i.ReadStringAt(p, j+1)
-				if err != nil {
-					return nil, j, err
-				}
-
-				// parse 'Mt' arguments
-				m, j, err = i.ReadMapAt(p, j+1)
-				if err != nil {
-					return nil, j, err
-				}
-
-				if len(pkg) > 0 {
-					obj, err := i.BuildObject(pkg, m)
-					if err != nil {
-						ans = append(ans, nil)
-					}
-					ans = append(ans, obj)
-				} else {
-					ans = append(ans, m)
-				}
+			tm.Type = pkg
+			v, err := c.Decode(tm)
+			if err != nil {
+				return nil, j, fmt.Errorf("decode %s: %v", pkg, err)
 			}
-		case 'V':
-			if p[j+1] == 't' {
-				j++
-				var arr []interface{}
+			return v, j, nil
+		}
+
+		// parse 'Mt' arguments
+		m, j, err = i.ReadMapAt(p, j+1)
+		if err != nil {
+			return nil, j, err
+		}
 
-				arr, j, err = i.ReadArrayAt(p, j+1)
-				if err != nil {
-					return nil, j, err
-				}
+		if len(pkg) > 0 {
+			obj, err := i.BuildObject(pkg, m)
+			if err != nil {
+				// unregistered or mismatched type, keep the map
+				return m, j, nil
+			}
+			return obj, j, nil
+		}
+		return m, j, nil
+	case 'V':
+		if j+1 >= len(p) || p[j+1] != 't' {
+			return nil, j, fmt.Errorf("parse value: expect 't' after 'V'")
+		}
+		j++
+		var arr []interface{}
+		var typ string
 
-				ans = append(ans, arr)
+		if i.keepTypes {
+			typ, _, err = i.ReadStringAt(p, j+1)
+			if err != nil {
+				return nil, j, err
 			}
 		}
+
+		arr, j, err = i.ReadArrayAt(p, j+1)
+		if err != nil {
+			return nil, j, err
+		}
+
+		if i.keepTypes {
+			return TypedList{Type: typ, Items: arr}, j, nil
+		}
+		return arr, j, nil
 	}
+}
 
-	return ans, j, nil
+// ReadReplyAt Read a reply from given bytes and begin index, return the value and headers, a fault is returned as *Fault.
+//
+// r x01 x00 (H b16 b8 <header-name> <value>)* (<value> | f <fault>) z
+func (i *DeserializerV1) ReadReplyAt(p []byte, begin int) (interface{}, map[string]interface{}, int, error) {
+	headers, idx, err := i.readReplyHeaders(p, begin)
+	if err != nil {
+		return nil, nil, idx, err
+	}
+
+	if idx < len(p) && p[idx] == 'f' {
+		args, j, err := i.ReadAt(p, idx+1)
+		if err != nil {
+			return nil, headers, j, err
+		}
+		return nil, headers, j, newFault(args)
+	}
+
+	val, idx, err := i.ReadValueAt(p, idx)
+	if err != nil {
+		return nil, headers, idx, err
+	}
+	idx++
+	if idx >= len(p) || p[idx] != 'z' {
+		return nil, headers, idx, fmt.Errorf("parse reply: expect 'z'")
+	}
+
+	return val, headers, idx, nil
+}
+
+// readReplyHeaders Read the start of a reply and its headers, return headers and the index of the value or fault.
+//
+// r x01 x00 (H b16 b8 <header-name> <value>)*
+func (i *DeserializerV1) readReplyHeaders(p []byte, begin int) (map[string]interface{}, int, error) {
+	if len(p) < begin+4 || p[begin] != 'r' {
+		return nil, begin, fmt.Errorf("parse reply: expect 'r'")
+	}
+	return i.readHeaders(p, begin+3)
+}
+
+// readHeaders Read headers from given bytes and begin index, return headers and the index after them.
+//
+// (H b16 b8 <header-name> <value>)*
+func (i *DeserializerV1) readHeaders(p []byte, begin int) (map[string]interface{}, int, error) {
+	var idx = begin
+	var headers map[string]interface{}
+
+	for idx < len(p) && p[idx] == 'H' {
+		name, j, err := i.ReadStringAt(p, idx+1)
+		if err != nil {
+			return nil, j, err
+		}
+		val, j, err := i.ReadValueAt(p, j+1)
+		if err != nil {
+			return nil, j, err
+		}
+		if headers == nil {
+			headers = make(map[string]interface{})
+		}
+		headers[name] = val
+		idx = j + 1
+	}
+	return headers, idx, nil
+}
+
+// ReadCallAt Read a method call from given bytes and begin index, return method name and arguments, headers are skipped.
+//
+// c x01 x00 (H b16 b8 <header-name> <value>)* m b16 b8 <method> <object> ... z
+func (i *DeserializerV1) ReadCallAt(p []byte, begin int) (string, []interface{}, int, error) {
+	if len(p) < begin+6 || p[begin] != 'c' {
+		return "", nil, begin, fmt.Errorf("parse call: expect 'c'")
+	}
+
+	_, idx, err := i.readHeaders(p, begin+3)
+	if err != nil {
+		return "", nil, idx, err
+	}
+	if idx >= len(p) || p[idx] != 'm' {
+		return "", nil, idx, fmt.Errorf("parse call: expect 'm'")
+	}
+
+	m, idx, err := i.ReadStringAt(p, idx+1)
+	if err != nil {
+		return "", nil, idx, err
+	}
+
+	args, idx, err := i.ReadAt(p, idx+1)
+	if err != nil {
+		return "", nil, idx, err
+	}
+	if idx >= len(p) || p[idx] != 'z' {
+		return "", nil, idx, fmt.Errorf("parse call: expect 'z'")
+	}
+
+	return m, args, idx, nil
 }
 
 // ReadBytesAt Read bytes from given bytes and begin index.
@@ -162,23 +304,22 @@ func (i *DeserializerV1) ReadBytesAt(p []byte, begin int) ([]byte, int, error) {
 //
 // After 'S' chart, find b16 b8 <string-value>
 func (i *DeserializerV1) ReadStringAt(p []byte, begin int) (string, int, error) {
-	var ans []byte
-
-	var idx = begin
-
 	// find string length
 	// b16 b8 <string-value>
-	l := int(p[idx])<<8 + int(p[idx+1])
-	idx++
-
-	ans = make([]byte, l)
-
-	for k := 0; k < l; k++ {
-		idx++
-		ans[k] = p[idx]
+	if begin+1 >= len(p) {
+		return "", begin, fmt.Errorf("parse string: unexpected end")
 	}
+	l := stringLen(p[begin:])
+	if begin+1+l >= len(p) {
+		return "", begin, fmt.Errorf("parse string: need %d bytes, %d left", l, len(p)-begin-2)
+	}
+	return string(p[begin+2 : begin+2+l]), begin + 1 + l, nil
+}
 
-	return string(ans), idx, nil
+// stringLen length of b16 b8 <string-value> at the beginning of p. It is the number of utf-8 bytes
+// of the value as written by printString, not of characters.
+func stringLen(p []byte) int {
+	return int(p[0])<<8 | int(p[1])
 }
 
 // ReadInt32At Read string from given bytes and begin index.
@@ -257,6 +398,30 @@ func (i *DeserializerV1) ReadMapAt(p []byte, begin int) (map[interface{}]interfa
 	return ans, idx, nil
 }
 
+// ReadTypedMapAt Read map entries in order from given bytes and begin index.
+//
+// After 'Mt <type>' chart, find <key> + <value> ...
+func (i *DeserializerV1) ReadTypedMapAt(p []byte, begin int) (TypedMap, int, error) {
+	var ans TypedMap
+	var params []interface{}
+	var idx = begin
+	var err error
+
+	params, idx, err = i.ReadAt(p, idx)
+	if err != nil {
+		return ans, idx, err
+	}
+	if len(params)%2 != 0 {
+		return ans, idx, fmt.Errorf("map has %d keys and %d values", len(params)/2+1, len(params)/2)
+	}
+
+	for j := 0; j < len(params); j += 2 {
+		ans.Entries = append(ans.Entries, MapEntry{Key: params[j], Value: params[j+1]})
+	}
+
+	return ans, idx, nil
+}
+
 // ReadArrayAt Read string from given bytes and begin index.
 //
 // After 'Vt <type> <size>' chart, find <value> + <value> ...
@@ -327,7 +492,11 @@ func (i *DeserializerV1) BuildObject(pkg string, data map[interface{}]interface{
 			if val == nil {
 				continue
 			}
-			tv.Elem().Field(j).Set(reflect.ValueOf(val))
+			fv, err := convertValue(val, t.Field(j).Type)
+			if err != nil {
+				return nil, fmt.Errorf("build %s field %s: %v", pkg, t.Field(j).Name, err)
+			}
+			tv.Elem().Field(j).Set(fv)
 		}
 	}
 
@@ -365,10 +534,17 @@ func (i *DeserializerV1) SetTypeMap(typeMap map[string]reflect.Type) {
 	i.typeMap = typeMap
 }
 
+// SetKeepTypes keep hessian type information, typed maps are read as TypedMap with class name
+// and entries in order instead of struct or map, lists are read as TypedList with list type.
+func (i *DeserializerV1) SetKeepTypes(keep bool) {
+	i.keepTypes = keep
+}
+
 // NewDeserializerV1 create DeserializerV1
 func NewDeserializerV1() *DeserializerV1 {
 	return &DeserializerV1{
 		version: 1,
 		typeMap: make(map[string]reflect.Type),
+		classes: DefaultClassRegistry,
 	}
 }
@@ -176,6 +176,33 @@ func TestDeserializerV1_ReadAt(t *testing.T) {
 			want1:   33,
 			wantErr: false,
 		},
+		{
+			name: "unregistered struct",
+			fields: fields{
+				version: 1,
+				r:       nil,
+				typeMap: map[string]reflect.Type{},
+			},
+			args: args{
+				p: []byte{
+					'r', 0x01, 0x00,
+					'M', 't',
+					0x00, 0x0c, 'p', 'a', 'c', 'k', 'a', 'g', 'e', '.', 'U', 's', 'e', 'r',
+					'S', 0x00, 0x04, 'n', 'a', 'm', 'e',
+					'S', 0x00, 0x03, 'w', 't', 'f',
+					'z',
+					'z',
+				},
+				begin: 0,
+			},
+			want: []interface{}{
+				map[interface{}]interface{}{
+					"name": "wtf",
+				},
+			},
+			want1:   33,
+			wantErr: false,
+		},
 		{
 			name: "array",
 			fields: fields{
@@ -764,6 +791,12 @@ func TestDeserializerV1_BuildObject(t *testing.T) {
 		Package `hessian:"package.Account"`
 		Name    string `hessian:"name"`
 	}
+	type Group struct {
+		Package `hessian:"package.Group"`
+		ID      int64   `hessian:"id"`
+		Owner   *User   `hessian:"owner"`
+		IDs     []int64 `hessian:"ids"`
+	}
 	type fields struct {
 		version int
 		r       io.Reader
@@ -822,6 +855,48 @@ func TestDeserializerV1_BuildObject(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "typed fields",
+			fields: fields{
+				version: 1,
+				r:       nil,
+				typeMap: map[string]reflect.Type{
+					"package.Group": reflect.TypeOf(&Group{}),
+				},
+			},
+			args: args{
+				pkg: "package.Group",
+				data: map[interface{}]interface{}{
+					"id":    int32(1),
+					"owner": User{Name: "ggwhite"},
+					"ids":   []interface{}{int32(1), int64(2)},
+				},
+			},
+			want: &Group{
+				ID:    1,
+				Owner: &User{Name: "ggwhite"},
+				IDs:   []int64{1, 2},
+			},
+			wantErr: false,
+		},
+		{
+			name: "wrong field type",
+			fields: fields{
+				version: 1,
+				r:       nil,
+				typeMap: map[string]reflect.Type{
+					"package.Group": reflect.TypeOf(&Group{}),
+				},
+			},
+			args: args{
+				pkg: "package.Group",
+				data: map[interface{}]interface{}{
+					"id": "abc",
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name: "no mapping",
 			fields: fields{
@@ -953,3 +1028,123 @@ func TestDeserializerV1_Reset(t *testing.T) {
 		})
 	}
 }
+
+func TestDeserializerV1_SetKeepTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       []byte
+		want    []interface{}
+		wantErr bool
+	}{
+		{
+			name: "typed map",
+			p: []byte{
+				'M', 't', 0x00, 0x04, 'U', 's', 'e', 'r',
+				'S', 0x00, 0x04, 'n', 'a', 'm', 'e', 'S', 0x00, 0x01, 'a',
+				'I', 0x00, 0x00, 0x00, 0x01, 'N',
+				'z',
+			},
+			want: []interface{}{TypedMap{Type: "User", Entries: []MapEntry{
+				{Key: "name", Value: "a"},
+				{Key: int32(1), Value: nil},
+			}}},
+		},
+		{
+			name: "typed list",
+			p: []byte{
+				'V', 't', 0x00, 0x04, '[', 'i', 'n', 't',
+				'l', 0x00, 0x00, 0x00, 0x01,
+				'I', 0x00, 0x00, 0x00, 0x01,
+				'z',
+			},
+			want: []interface{}{TypedList{Type: "[int", Items: []interface{}{int32(1)}}},
+		},
+		{
+			name:    "odd map",
+			p:       []byte{'M', 't', 0x00, 0x00, 'N', 'z'},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := NewDeserializerV1()
+			i.SetKeepTypes(true)
+			got, _, err := i.ReadAt(tt.p, 0)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeserializerV1.ReadAt() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DeserializerV1.ReadAt() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeserializerV1_ReadValueAt(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       []byte
+		want    interface{}
+		want1   int
+		wantErr bool
+	}{
+		{name: "int", p: []byte{'I', 0x00, 0x00, 0x00, 0x01, 'N'}, want: int32(1), want1: 4},
+		{name: "null", p: []byte{'N', 'N'}, want: nil, want1: 0},
+		{name: "list", p: []byte{'V', 't', 0x00, 0x00, 'l', 0x00, 0x00, 0x00, 0x01, 'T', 'z'}, want: []interface{}{true}, want1: 10},
+		{name: "map without type", p: []byte{'M', 'N', 'N', 'z'}, wantErr: true},
+		{name: "unknown tag", p: []byte{'z'}, wantErr: true},
+		{name: "end", p: []byte{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1, err := NewDeserializerV1().ReadValueAt(tt.p, 0)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeserializerV1.ReadValueAt() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) || got1 != tt.want1 {
+				t.Errorf("DeserializerV1.ReadValueAt() = %v, %v, want %v, %v", got, got1, tt.want, tt.want1)
+			}
+		})
+	}
+}
+
+func TestDeserializerV1_ReadReplyAt(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       []byte
+		wantErr bool
+	}{
+		{name: "not reply", p: []byte{'c', 0x01, 0x00, 'N', 'z'}, wantErr: true},
+		{name: "missing z", p: []byte{'r', 0x01, 0x00, 'N', 'N'}, wantErr: true},
+		{name: "two values", p: []byte{'r', 0x01, 0x00, 'N', 'N', 'z'}, wantErr: true},
+		{name: "ok", p: []byte{'r', 0x01, 0x00, 'N', 'z'}, wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := NewDeserializerV1().ReadReplyAt(tt.p, 0); (err != nil) != tt.wantErr {
+				t.Errorf("DeserializerV1.ReadReplyAt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDeserializerV1_ReadAt_Headers(t *testing.T) {
+	p := []byte{
+		'r', 0x01, 0x00,
+		'H', 0x00, 0x01, 'a', 'S', 0x00, 0x01, 'x',
+		'I', 0x00, 0x00, 0x00, 0x01,
+		'z',
+	}
+	got, _, err := NewDeserializerV1().ReadAt(p, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{int32(1)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DeserializerV1.ReadAt() got = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,422 @@
+package hessian
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// dumpPreview max characters (or bytes of binary) printed for a value
+const dumpPreview = 64
+
+// Dump write an annotated tree of hessian payload p to w.
+//
+// Each line is the hex offset, the tag character and the decoded value with its length
+// and class name or list type. p can be a call, a reply, a fault or bare values.
+// Dump stops at the first unknown tag or truncated value, marks the offset with "^" and
+// returns the error, so partial payloads still print up to where decoding stopped.
+//
+// Only V1 payloads are supported.
+//
+//	000000  c call 1.0
+//	000003    m method "getUser" len 7
+//	00000d    S string "ggwhite" len 7
+//	000017  z end of call
+func Dump(w io.Writer, p []byte, v version) error {
+	if v != V1 {
+		return fmt.Errorf("dump: unsupported hessian version")
+	}
+
+	d := &dumper{w: w, p: p}
+	if err := d.dump(); err != nil {
+		fmt.Fprintf(w, "%06x  %s^ stopped: %v\n", d.pos, d.indent(), err)
+		return fmt.Errorf("dump: offset %d: %v", d.pos, err)
+	}
+	return nil
+}
+
+// dumper state of Dump
+type dumper struct {
+	w     io.Writer
+	p     []byte
+	pos   int
+	depth int
+}
+
+func (d *dumper) indent() string {
+	return strings.Repeat("  ", d.depth)
+}
+
+// line print a tag at offset
+func (d *dumper) line(at int, tag byte, format string, args ...interface{}) {
+	fmt.Fprintf(d.w, "%06x  %s%c %s\n", at, d.indent(), tag, fmt.Sprintf(format, args...))
+}
+
+// need check n bytes left
+func (d *dumper) need(n int) error {
+	if d.pos+n > len(d.p) {
+		return fmt.Errorf("truncated, need %d bytes, %d left", n, len(d.p)-d.pos)
+	}
+	return nil
+}
+
+// peek return next byte, 0 at the end
+func (d *dumper) peek() byte {
+	if d.pos < len(d.p) {
+		return d.p[d.pos]
+	}
+	return 0
+}
+
+// uint read n bytes big-endian
+func (d *dumper) uint(n int) (uint64, error) {
+	if err := d.need(n); err != nil {
+		return 0, err
+	}
+	var ans uint64
+	for k := 0; k < n; k++ {
+		ans = ans<<8 | uint64(d.p[d.pos])
+		d.pos++
+	}
+	return ans, nil
+}
+
+// string read b16 b8 utf8-string, length is in bytes as stringLen
+func (d *dumper) string() (string, int, error) {
+	if err := d.need(2); err != nil {
+		return "", 0, err
+	}
+	l := stringLen(d.p[d.pos:])
+	d.pos += 2
+	if err := d.need(l); err != nil {
+		return "", 0, err
+	}
+	d.pos += l
+	return string(d.p[d.pos-l : d.pos]), l, nil
+}
+
+// quote preview of string
+func quote(s string) string {
+	if utf8.RuneCountInString(s) <= dumpPreview {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%q...", string([]rune(s)[:dumpPreview]))
+}
+
+// end read 'z' of a container, printed at the container's depth
+func (d *dumper) end(what string, n int, unit string) error {
+	if err := d.need(1); err != nil {
+		return err
+	}
+	d.depth--
+	d.line(d.pos, 'z', "end of %s, %d %s", what, n, unit)
+	d.pos++
+	return nil
+}
+
+// dump calls, replies and values until the end of p
+func (d *dumper) dump() error {
+	for d.pos < len(d.p) {
+		var err error
+		switch {
+		case d.p[d.pos] == 'c':
+			err = d.call()
+		case d.p[d.pos] == 'r' && d.pos+1 < len(d.p) && d.p[d.pos+1] != 't':
+			err = d.reply()
+		default:
+			err = d.value()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// call c x01 x00 header* m b16 b8 method value* z
+func (d *dumper) call() error {
+	at := d.pos
+	d.pos++
+	ver, err := d.uint(2)
+	if err != nil {
+		return err
+	}
+	d.line(at, 'c', "call %d.%d", ver>>8, ver&0xff)
+	d.depth++
+
+	var n int
+	for d.peek() != 'z' {
+		switch d.peek() {
+		case 'H':
+			err = d.header()
+		case 'm':
+			at := d.pos
+			d.pos++
+			var m string
+			var l int
+			if m, l, err = d.string(); err == nil {
+				d.line(at, 'm', "method %s len %d", quote(m), l)
+			}
+		default:
+			n++
+			err = d.value()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return d.end("call", n, "arguments")
+}
+
+// reply r x01 x00 header* (value | f fault) z
+func (d *dumper) reply() error {
+	at := d.pos
+	d.pos++
+	ver, err := d.uint(2)
+	if err != nil {
+		return err
+	}
+	d.line(at, 'r', "reply %d.%d", ver>>8, ver&0xff)
+	d.depth++
+
+	for d.peek() == 'H' {
+		if err := d.header(); err != nil {
+			return err
+		}
+	}
+
+	if d.peek() == 'f' {
+		d.line(d.pos, 'f', "fault")
+		d.pos++
+		d.depth++
+		var n int
+		for d.peek() != 'z' {
+			if err := d.value(); err != nil {
+				return err
+			}
+			n++
+		}
+		// z ends both the fault and the reply
+		d.depth--
+		return d.end("reply", n/2, "fault entries")
+	}
+
+	var n int
+	for d.peek() != 'z' {
+		if err := d.value(); err != nil {
+			return err
+		}
+		n++
+	}
+	return d.end("reply", n, "values")
+}
+
+// header H b16 b8 name value
+func (d *dumper) header() error {
+	at := d.pos
+	d.pos++
+	name, l, err := d.string()
+	if err != nil {
+		return err
+	}
+	d.line(at, 'H', "header %s len %d", quote(name), l)
+	d.depth++
+	defer func() { d.depth-- }()
+	return d.value()
+}
+
+// value read one value and print it
+func (d *dumper) value() error {
+	at := d.pos
+	if err := d.need(1); err != nil {
+		return err
+	}
+	tag := d.p[d.pos]
+	d.pos++
+
+	switch tag {
+	default:
+		d.pos = at
+		return fmt.Errorf("unknown tag %q", tag)
+	case 'N':
+		d.line(at, tag, "null")
+	case 'T':
+		d.line(at, tag, "true")
+	case 'F':
+		d.line(at, tag, "false")
+	case 'I':
+		v, err := d.uint(4)
+		if err != nil {
+			return err
+		}
+		d.line(at, tag, "int %d", int32(v))
+	case 'L':
+		v, err := d.uint(8)
+		if err != nil {
+			return err
+		}
+		d.line(at, tag, "long %d", int64(v))
+	case 'D':
+		v, err := d.uint(8)
+		if err != nil {
+			return err
+		}
+		d.line(at, tag, "double %v", math.Float64frombits(v))
+	case 'd':
+		v, err := d.uint(8)
+		if err != nil {
+			return err
+		}
+		ms := int64(v)
+		d.line(at, tag, "date %s", time.Unix(ms/1e3, ms%1e3*1e6).UTC().Format(time.RFC3339Nano))
+	case 'R':
+		v, err := d.uint(4)
+		if err != nil {
+			return err
+		}
+		d.line(at, tag, "ref %d", int32(v))
+	case 'S', 's', 'X', 'x':
+		s, l, err := d.string()
+		if err != nil {
+			return err
+		}
+		kind := "string"
+		if tag == 'X' || tag == 'x' {
+			kind = "xml"
+		}
+		if tag == 's' || tag == 'x' {
+			kind += " chunk"
+		}
+		d.line(at, tag, "%s %s len %d", kind, quote(s), l)
+	case 'B', 'b':
+		l, err := d.uint(2)
+		if err != nil {
+			return err
+		}
+		if err := d.need(int(l)); err != nil {
+			return err
+		}
+		b := d.p[d.pos : d.pos+int(l)]
+		d.pos += int(l)
+		kind := "binary"
+		if tag == 'b' {
+			kind += " chunk"
+		}
+		if len(b) > dumpPreview {
+			d.line(at, tag, "%s %s... len %d", kind, hex.EncodeToString(b[:dumpPreview]), l)
+		} else {
+			d.line(at, tag, "%s %s len %d", kind, hex.EncodeToString(b), l)
+		}
+	case 'M':
+		return d.mapValue(at)
+	case 'V':
+		return d.list(at)
+	case 'r':
+		// r t b16 b8 type-name S b16 b8 url
+		if d.peek() != 't' {
+			d.pos = at
+			return fmt.Errorf("remote without type")
+		}
+		d.pos++
+		typ, _, err := d.string()
+		if err != nil {
+			return err
+		}
+		d.line(at, tag, "remote type %s", quote(typ))
+		d.depth++
+		defer func() { d.depth-- }()
+		return d.value()
+	}
+
+	// a chunk is followed by the rest of the value
+	if tag == 's' || tag == 'x' || tag == 'b' {
+		next := d.peek()
+		if next != tag && next != tag-'a'+'A' {
+			if err := d.need(1); err != nil {
+				return err
+			}
+			return fmt.Errorf("chunk %q followed by %q", tag, next)
+		}
+		return d.value()
+	}
+	return nil
+}
+
+// typeName read optional t b16 b8 type-name
+func (d *dumper) typeName() (string, error) {
+	if d.peek() != 't' {
+		return "", nil
+	}
+	d.pos++
+	typ, _, err := d.string()
+	return typ, err
+}
+
+// mapValue M t b16 b8 type-name (key value)* z
+func (d *dumper) mapValue(at int) error {
+	typ, err := d.typeName()
+	if err != nil {
+		return err
+	}
+	if len(typ) > 0 {
+		d.line(at, 'M', "map type %s", quote(typ))
+	} else {
+		d.line(at, 'M', "map")
+	}
+	d.depth++
+
+	var n int
+	for d.peek() != 'z' {
+		if err := d.value(); err != nil {
+			return err
+		}
+		n++
+	}
+	if n%2 != 0 {
+		return fmt.Errorf("map with %d keys and %d values", n/2+1, n/2)
+	}
+	return d.end("map", n/2, "entries")
+}
+
+// list V t b16 b8 type-name l b32 b24 b16 b8 value* z
+func (d *dumper) list(at int) error {
+	typ, err := d.typeName()
+	if err != nil {
+		return err
+	}
+	l := -1
+	if d.peek() == 'l' {
+		d.pos++
+		v, err := d.uint(4)
+		if err != nil {
+			return err
+		}
+		l = int(int32(v))
+	}
+
+	desc := "list"
+	if len(typ) > 0 {
+		desc += " type " + quote(typ)
+	}
+	if l >= 0 {
+		desc += fmt.Sprintf(" len %d", l)
+	}
+	d.line(at, 'V', "%s", desc)
+	d.depth++
+
+	var n int
+	for d.peek() != 'z' {
+		if err := d.value(); err != nil {
+			return err
+		}
+		n++
+	}
+	if l >= 0 && n != l {
+		return fmt.Errorf("list length %d, got %d values", l, n)
+	}
+	return d.end("list", n, "values")
+}
@@ -0,0 +1,182 @@
+package hessian
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       []byte
+		v       version
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "call",
+			p: []byte{
+				'c', 0x01, 0x00,
+				'H', 0x00, 0x02, 'i', 'd', 'I', 0x00, 0x00, 0x00, 0x01,
+				'm', 0x00, 0x07, 'g', 'e', 't', 'U', 's', 'e', 'r',
+				'S', 0x00, 0x07, 'g', 'g', 'w', 'h', 'i', 't', 'e',
+				'z',
+			},
+			v: V1,
+			want: `000000  c call 1.0
+000003    H header "id" len 2
+000008      I int 1
+00000d    m method "getUser" len 7
+000017    S string "ggwhite" len 7
+000021  z end of call, 1 arguments
+`,
+		},
+		{
+			name: "reply",
+			p: []byte{
+				'r', 0x01, 0x00,
+				'M', 't', 0x00, 0x04, 'U', 's', 'e', 'r',
+				'S', 0x00, 0x04, 'l', 'i', 's', 't',
+				'V', 't', 0x00, 0x04, '[', 'i', 'n', 't', 'l', 0x00, 0x00, 0x00, 0x02,
+				'L', 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02,
+				'N',
+				'z',
+				'z',
+				'z',
+			},
+			v: V1,
+			want: `000000  r reply 1.0
+000003    M map type "User"
+00000b      S string "list" len 4
+000012      V list type "[int" len 2
+00001f        L long 2
+000028        N null
+000029      z end of list, 2 values
+00002a    z end of map, 1 entries
+00002b  z end of reply, 1 values
+`,
+		},
+		{
+			name: "fault",
+			p: []byte{
+				'r', 0x01, 0x00, 'f',
+				'S', 0x00, 0x04, 'c', 'o', 'd', 'e',
+				'S', 0x00, 0x02, 'N', 'o',
+				'z',
+			},
+			v: V1,
+			want: `000000  r reply 1.0
+000003    f fault
+000004      S string "code" len 4
+00000b      S string "No" len 2
+000010  z end of reply, 1 fault entries
+`,
+		},
+		{
+			name: "values",
+			p: []byte{
+				'T', 'F',
+				'D', 0x3f, 0xf8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				'd', 0x00, 0x00, 0x01, 0x6a, 0x31, 0x40, 0x32, 0xa0,
+				's', 0x00, 0x07, 0xe4, 0xbd, 0xa0, 0xf0, 0x9f, 0x98, 0x80,
+				'S', 0x00, 0x01, 'a',
+				'B', 0x00, 0x02, 0xca, 0xfe,
+				'R', 0x00, 0x00, 0x00, 0x00,
+			},
+			v: V1,
+			want: `000000  T true
+000001  F false
+000002  D double 1.5
+00000b  d date 2019-04-18T16:20:52Z
+000014  s string chunk "你😀" len 7
+00001e  S string "a" len 1
+000022  B binary cafe len 2
+000027  R ref 0
+`,
+		},
+		{
+			name: "truncated",
+			p: []byte{
+				'r', 0x01, 0x00,
+				'V', 'l', 0x00, 0x00, 0x00, 0x02,
+				'S', 0x00, 0x05, 'h', 'e',
+			},
+			v: V1,
+			want: `000000  r reply 1.0
+000003    V list len 2
+00000c      ^ stopped: truncated, need 5 bytes, 2 left
+`,
+			wantErr: true,
+		},
+		{
+			name: "multi-byte runes",
+			p: []byte{
+				'V', 't', 0x00, 0x07, '[', 'o', 'b', 'j', 'e', 'c', 't', 'l', 0x00, 0x00, 0x00, 0x02,
+				'S', 0x00, 0x0d, 'h', 0xc3, 0xa9, 'l', 'l', 'o', ' ', 'w', 0xc3, 0xb6, 'r', 'l', 'd',
+				'S', 0x00, 0x01, 'x',
+				'z',
+			},
+			v: V1,
+			want: `000000  V list type "[object" len 2
+000010    S string "héllo wörld" len 13
+000020    S string "x" len 1
+000024  z end of list, 2 values
+`,
+		},
+		{
+			name: "unknown tag",
+			p:    []byte{'N', 0x99},
+			v:    V1,
+			want: `000000  N null
+000001  ^ stopped: unknown tag '\u0099'
+`,
+			wantErr: true,
+		},
+		{
+			name:    "V2",
+			p:       []byte{'N'},
+			v:       V2,
+			want:    ``,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			if err := Dump(w, tt.p, tt.v); (err != nil) != tt.wantErr {
+				t.Errorf("Dump() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got := w.String(); got != tt.want {
+				t.Errorf("Dump() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDump_Preview(t *testing.T) {
+	p := append([]byte{'S', 0x00, 0x64}, strings.Repeat("a", 100)...)
+	w := &bytes.Buffer{}
+	if err := Dump(w, p, V1); err != nil {
+		t.Fatal(err)
+	}
+	want := `000000  S string "` + strings.Repeat("a", dumpPreview) + `"... len 100` + "\n"
+	if got := w.String(); got != want {
+		t.Errorf("Dump() = %s, want %s", got, want)
+	}
+}
+
+func TestDump_Marshal(t *testing.T) {
+	p, err := Marshal([]interface{}{"héllo wörld", "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &bytes.Buffer{}
+	if err := Dump(w, p, V1); err != nil {
+		t.Fatalf("Dump() error = %v\n%s", err, w)
+	}
+	if !strings.Contains(w.String(), `"héllo wörld" len 13`) {
+		t.Errorf("Dump() =\n%s", w)
+	}
+}
@@ -0,0 +1,63 @@
+package hessian
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// RegisterEnum register java enum class to DefaultClassRegistry, constants are all constants of a go
+// string type, ex: RegisterEnum("lab.ggw.Color", Red, Green)
+func RegisterEnum(class string, constants ...interface{}) error {
+	return DefaultClassRegistry.RegisterEnum(class, constants...)
+}
+
+// RegisterEnum register java enum class, constants are all constants of a go string type. The go type
+// is written as a map of class with the constant as "name", only the declared constants are written and read.
+func (r *ClassRegistry) RegisterEnum(class string, constants ...interface{}) error {
+	c, err := enumCodec(class, constants)
+	if err != nil {
+		return err
+	}
+	return r.Register(c)
+}
+
+// enumCodec class codec of java enum class of constants
+func enumCodec(class string, constants []interface{}) (ClassCodec, error) {
+	if len(constants) == 0 {
+		return ClassCodec{}, fmt.Errorf("enum %s: no constants", class)
+	}
+	t := reflect.TypeOf(constants[0])
+	if t == nil || t.Kind() != reflect.String {
+		return ClassCodec{}, fmt.Errorf("enum %s: %v is not a string type", class, t)
+	}
+	names := make(map[string]bool)
+	for _, v := range constants {
+		if reflect.TypeOf(v) != t {
+			return ClassCodec{}, fmt.Errorf("enum %s: constant %v is not of %v", class, v, t)
+		}
+		names[reflect.ValueOf(v).String()] = true
+	}
+
+	return ClassCodec{
+		Class: class,
+		Type:  t,
+		Encode: func(v interface{}) ([]MapEntry, error) {
+			name := reflect.ValueOf(v).String()
+			if !names[name] {
+				return nil, fmt.Errorf("invalid constant %q", name)
+			}
+			return []MapEntry{{Key: "name", Value: name}}, nil
+		},
+		Decode: func(m TypedMap) (interface{}, error) {
+			v, _ := m.Get("name")
+			name, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("name is not a string: %v", v)
+			}
+			if !names[name] {
+				return nil, fmt.Errorf("invalid constant %q", name)
+			}
+			return reflect.ValueOf(name).Convert(t).Interface(), nil
+		},
+	}, nil
+}
@@ -0,0 +1,113 @@
+package hessian
+
+import (
+	"reflect"
+	"testing"
+)
+
+type enumColor string
+
+const (
+	enumRed   enumColor = "RED"
+	enumGreen enumColor = "GREEN"
+)
+
+func TestClassRegistry_RegisterEnum(t *testing.T) {
+	tests := []struct {
+		name      string
+		class     string
+		constants []interface{}
+		wantErr   bool
+	}{
+		{name: "ok", class: "lab.ggw.Color", constants: []interface{}{enumRed, enumGreen}, wantErr: false},
+		{name: "no class", class: "", constants: []interface{}{enumRed}, wantErr: true},
+		{name: "no constants", class: "lab.ggw.Color", constants: nil, wantErr: true},
+		{name: "not string", class: "lab.ggw.Color", constants: []interface{}{1}, wantErr: true},
+		{name: "nil", class: "lab.ggw.Color", constants: []interface{}{nil}, wantErr: true},
+		{name: "mixed types", class: "lab.ggw.Color", constants: []interface{}{enumRed, "GREEN"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := NewClassRegistry().RegisterEnum(tt.class, tt.constants...); (err != nil) != tt.wantErr {
+				t.Errorf("ClassRegistry.RegisterEnum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEnum(t *testing.T) {
+	r := NewClassRegistry()
+	if err := r.RegisterEnum("lab.ggw.Color", enumRed, enumGreen); err != nil {
+		t.Fatal(err)
+	}
+	codec, err := NewCodec(&CodecConfig{Version: V1, Classes: r})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wire := []byte{
+		'M', 't', 0, 13, 'l', 'a', 'b', '.', 'g', 'g', 'w', '.', 'C', 'o', 'l', 'o', 'r',
+		'S', 0, 4, 'n', 'a', 'm', 'e', 'S', 0, 3, 'R', 'E', 'D',
+		'z',
+	}
+
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    []byte
+		wantErr bool
+	}{
+		{name: "value", v: enumRed, want: wire, wantErr: false},
+		{name: "pointer", v: func() *enumColor { c := enumRed; return &c }(), want: wire, wantErr: false},
+		{name: "nil pointer", v: (*enumColor)(nil), want: []byte{'N'}, wantErr: false},
+		{name: "undeclared", v: enumColor("BLUE"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := codec.Marshal(tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Codec.Marshal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Codec.Marshal() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	var v interface{}
+	if err := codec.Unmarshal(wire, &v); err != nil || v != enumRed {
+		t.Errorf("Codec.Unmarshal() = %#v, %v, want %#v", v, err, enumRed)
+	}
+	var c enumColor
+	if err := codec.Unmarshal(wire, &c); err != nil || c != enumRed {
+		t.Errorf("Codec.Unmarshal() = %#v, %v, want %#v", c, err, enumRed)
+	}
+	var p *enumColor
+	if err := codec.Unmarshal(wire, &p); err != nil || p == nil || *p != enumRed {
+		t.Errorf("Codec.Unmarshal() = %v, %v, want %#v", p, err, enumRed)
+	}
+
+	// in a struct
+	type paint struct {
+		Package `hessian:"lab.ggw.Paint"`
+		Color   enumColor  `hessian:"color"`
+		Accent  *enumColor `hessian:"accent"`
+	}
+	green := enumGreen
+	b, err := codec.Marshal(paint{Color: enumRed, Accent: &green})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got paint
+	if err := codec.Unmarshal(b, &got); err != nil || got.Color != enumRed || got.Accent == nil || *got.Accent != enumGreen {
+		t.Errorf("Codec.Unmarshal() = %#v, %v", got, err)
+	}
+
+	// constant not declared
+	o := NewSerializerV1()
+	o.WriteTypedMap(TypedMap{Type: "lab.ggw.Color", Entries: []MapEntry{{Key: "name", Value: "BLUE"}}})
+	if err := codec.Unmarshal(o.buf.Bytes(), &v); err == nil {
+		t.Errorf("Codec.Unmarshal() error = nil, want error")
+	}
+}
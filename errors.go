@@ -0,0 +1,58 @@
+package hessian
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// UnsupportedTypeError returned by SerializerV1 when a value of Type can not be written, ex: chan,
+// func, complex, uintptr and unsafe.Pointer
+type UnsupportedTypeError struct {
+	Type reflect.Type
+	// Path of the value in the written value, ex: args[1].Items[2].Price, empty for the value itself
+	Path string
+}
+
+// Error implements error
+func (e *UnsupportedTypeError) Error() string {
+	if len(e.Path) == 0 {
+		return fmt.Sprintf("hessian: unsupported type %s", e.Type)
+	}
+	return fmt.Sprintf("hessian: unsupported type %s at %s", e.Type, e.Path)
+}
+
+// UnsupportedValueError returned by SerializerV1 when a value of a supported type can not be
+// written, ex: uint64 which overflows long
+type UnsupportedValueError struct {
+	Value interface{}
+	// Reason why the value is not supported
+	Reason string
+	// Path of the value in the written value, ex: args[1].Items[2].Price, empty for the value itself
+	Path string
+}
+
+// Error implements error
+func (e *UnsupportedValueError) Error() string {
+	if len(e.Path) == 0 {
+		return fmt.Sprintf("hessian: unsupported value %v: %s", e.Value, e.Reason)
+	}
+	return fmt.Sprintf("hessian: unsupported value %v at %s: %s", e.Value, e.Path, e.Reason)
+}
+
+// withPath prepend elem, a field name or an [index], to the path of an unsupported type or value error
+func withPath(err error, elem string) error {
+	var path *string
+	switch e := err.(type) {
+	case *UnsupportedTypeError:
+		path = &e.Path
+	case *UnsupportedValueError:
+		path = &e.Path
+	default:
+		return err
+	}
+	if len(*path) > 0 && (*path)[0] != '[' {
+		elem += "."
+	}
+	*path = elem + *path
+	return err
+}
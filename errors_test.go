@@ -0,0 +1,70 @@
+package hessian
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestUnsupportedTypeError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		e    *UnsupportedTypeError
+		want string
+	}{
+		{name: "value", e: &UnsupportedTypeError{Type: reflect.TypeOf(1i)}, want: "hessian: unsupported type complex128"},
+		{name: "path", e: &UnsupportedTypeError{Type: reflect.TypeOf(1i), Path: "Items[0].Price"}, want: "hessian: unsupported type complex128 at Items[0].Price"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.e.Error(); got != tt.want {
+				t.Errorf("UnsupportedTypeError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnsupportedValueError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		e    *UnsupportedValueError
+		want string
+	}{
+		{name: "value", e: &UnsupportedValueError{Value: uint64(1 << 63), Reason: "overflows long"}, want: "hessian: unsupported value 9223372036854775808: overflows long"},
+		{name: "path", e: &UnsupportedValueError{Value: uint64(1 << 63), Reason: "overflows long", Path: "ID"}, want: "hessian: unsupported value 9223372036854775808 at ID: overflows long"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.e.Error(); got != tt.want {
+				t.Errorf("UnsupportedValueError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithPath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		elem string
+		want string
+	}{
+		{name: "empty", path: "", elem: "Items", want: "Items"},
+		{name: "index", path: "[0]", elem: "Items", want: "Items[0]"},
+		{name: "field", path: "Price", elem: "[0]", want: "[0].Price"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := withPath(&UnsupportedTypeError{Type: reflect.TypeOf(1i), Path: tt.path}, tt.elem)
+			if got := err.(*UnsupportedTypeError).Path; got != tt.want {
+				t.Errorf("withPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// other errors are not changed
+	err := fmt.Errorf("oops")
+	if got := withPath(err, "Items"); got != err {
+		t.Errorf("withPath() = %v, want %v", got, err)
+	}
+}
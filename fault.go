@@ -0,0 +1,86 @@
+package hessian
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Fault a hessian fault replied by service, returned as error by Invoke.
+//
+// Code is one of ProtocolException, NoSuchObjectException, NoSuchMethodException,
+// RequireHeaderException, ServiceException, or the code set by service.
+type Fault struct {
+	Code    string
+	Message string
+	Detail  interface{}
+}
+
+// Error implements error
+func (f *Fault) Error() string {
+	return fmt.Sprintf("Call Service Error, Exception: %s, Message: %s", f.Code, f.Message)
+}
+
+// FaultError an error which declare the fault replied by Server, ex: code, message and the java
+// exception in detail
+type FaultError interface {
+	error
+	HessianFault() *Fault
+}
+
+// faultOf map an error returned by service method to fault.
+//
+// A FaultError or *Fault in the chain (by Unwrap or Cause) is replied as is. An error type with
+// Package declare its java exception class, it is replied as the detail with its tagged fields.
+// Other errors are replied as ServiceException with the error message.
+func faultOf(err error) *Fault {
+	for e := err; e != nil; e = unwrap(e) {
+		if fe, ok := e.(FaultError); ok {
+			if f := fe.HessianFault(); f != nil {
+				return f
+			}
+		}
+	}
+
+	for e := err; e != nil; e = unwrap(e) {
+		if f, ok := e.(*Fault); ok {
+			return f
+		}
+	}
+
+	f := &Fault{Code: "ServiceException", Message: err.Error()}
+	for e := err; e != nil; e = unwrap(e) {
+		if len(PackageOf(reflect.TypeOf(e))) > 0 {
+			f.Detail = e
+			break
+		}
+	}
+	return f
+}
+
+// unwrap the error wrapped by err, by Unwrap() error or Cause() error, nil if none
+func unwrap(err error) error {
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return e.Unwrap()
+	case interface{ Cause() error }:
+		return e.Cause()
+	}
+	return nil
+}
+
+// newFault build Fault from fault entries: code, message and detail
+func newFault(args []interface{}) *Fault {
+	f := &Fault{}
+	for j := 0; j+1 < len(args); j += 2 {
+		key, _ := args[j].(string)
+		switch key {
+		case "code":
+			f.Code, _ = args[j+1].(string)
+		case "message":
+			f.Message, _ = args[j+1].(string)
+		case "detail":
+			f.Detail = args[j+1]
+		}
+	}
+	return f
+}
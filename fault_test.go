@@ -0,0 +1,124 @@
+package hessian
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFault_Error(t *testing.T) {
+	f := &Fault{Code: "ServiceException", Message: "boom"}
+	if got, want := f.Error(), "Call Service Error, Exception: ServiceException, Message: boom"; got != want {
+		t.Errorf("Fault.Error() = %v, want %v", got, want)
+	}
+}
+
+func TestDeserializerV1_ReadAt_Fault(t *testing.T) {
+	p := []byte{
+		'r', 0x01, 0x00, 'f',
+		'S', 0x00, 0x04, 'c', 'o', 'd', 'e',
+		'S', 0x00, 0x15, 'N', 'o', 'S', 'u', 'c', 'h', 'M', 'e', 't', 'h', 'o', 'd', 'E', 'x', 'c', 'e', 'p', 't', 'i', 'o', 'n',
+		'S', 0x00, 0x07, 'm', 'e', 's', 's', 'a', 'g', 'e',
+		'S', 0x00, 0x03, 'f', 'o', 'o',
+		'S', 0x00, 0x06, 'd', 'e', 't', 'a', 'i', 'l',
+		'N',
+		'z',
+	}
+	_, _, err := NewDeserializerV1().ReadAt(p, 0)
+	f, ok := err.(*Fault)
+	if !ok {
+		t.Fatalf("DeserializerV1.ReadAt() error = %v, want *Fault", err)
+	}
+	want := &Fault{Code: "NoSuchMethodException", Message: "foo"}
+	if !reflect.DeepEqual(f, want) {
+		t.Errorf("DeserializerV1.ReadAt() error = %#v, want %#v", f, want)
+	}
+}
+
+type notFoundError struct {
+	Package `hessian:"lab.ggw.NotFoundException"`
+	Message string `hessian:"detailMessage"`
+}
+
+func (e *notFoundError) Error() string {
+	return e.Message
+}
+
+type codeError struct {
+	code string
+}
+
+func (e codeError) Error() string {
+	return "code " + e.code
+}
+
+func (e codeError) HessianFault() *Fault {
+	if len(e.code) == 0 {
+		return nil
+	}
+	return &Fault{Code: e.code, Message: "mapped"}
+}
+
+// wrapError wrap err by Unwrap
+type wrapError struct {
+	msg string
+	err error
+}
+
+func (e *wrapError) Error() string { return e.msg + ": " + e.err.Error() }
+func (e *wrapError) Unwrap() error { return e.err }
+
+// causeError wrap err by Cause as github.com/pkg/errors
+type causeError struct {
+	err error
+}
+
+func (e causeError) Error() string { return "cause: " + e.err.Error() }
+func (e causeError) Cause() error  { return e.err }
+
+func TestFaultOf(t *testing.T) {
+	notFound := &notFoundError{Message: "no user"}
+	tests := []struct {
+		name string
+		err  error
+		want *Fault
+	}{
+		{
+			name: "error",
+			err:  errors.New("boom"),
+			want: &Fault{Code: "ServiceException", Message: "boom"},
+		},
+		{
+			name: "fault",
+			err:  &wrapError{msg: "wrap", err: &Fault{Code: "RequireHeaderException", Message: "header"}},
+			want: &Fault{Code: "RequireHeaderException", Message: "header"},
+		},
+		{
+			name: "FaultError",
+			err:  codeError{code: "ProtocolException"},
+			want: &Fault{Code: "ProtocolException", Message: "mapped"},
+		},
+		{
+			name: "FaultError without fault",
+			err:  codeError{},
+			want: &Fault{Code: "ServiceException", Message: "code "},
+		},
+		{
+			name: "Cause",
+			err:  causeError{&Fault{Code: "NoSuchObjectException", Message: "gone"}},
+			want: &Fault{Code: "NoSuchObjectException", Message: "gone"},
+		},
+		{
+			name: "java exception",
+			err:  &wrapError{msg: "get user", err: notFound},
+			want: &Fault{Code: "ServiceException", Message: "get user: no user", Detail: notFound},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := faultOf(tt.err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("faultOf() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package hessian
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GatewayConfig config for NewGateway
+type GatewayConfig struct {
+	Proxy *Proxy
+
+	// Hints optional, argument type hints of each method, ex: "getUser": {"long", "lab.ggw.User"}.
+	//
+	// A hint is a hessian type: boolean, int, long, double, string, date, binary, object,
+	// a class name for objects, or a list type like "[long" which is also applied to items.
+	Hints map[string][]string
+
+	// MaxBodySize optional, max bytes of a request body, default 1 MiB
+	MaxBodySize int64
+}
+
+// Validate check config
+func (c *GatewayConfig) Validate() error {
+	if c.Proxy == nil {
+		return fmt.Errorf("Gateway Config: Proxy is empty")
+	}
+
+	if c.MaxBodySize < 0 {
+		return fmt.Errorf("Gateway Config: MaxBodySize must not be negative")
+	}
+	if c.MaxBodySize == 0 {
+		c.MaxBodySize = 1 << 20
+	}
+	return nil
+}
+
+// Gateway an http.Handler which serve POST /{method} with a JSON array of arguments, invoke the
+// method through Proxy and write the reply as JSON.
+//
+// Arguments use the syntax of FromJSON, hints convert plain JSON values to the hessian type of
+// the method. A fault is written as {"code": ..., "message": ..., "detail": ...} with status
+// 404 for NoSuchMethodException and NoSuchObjectException, 500 for the others.
+// Invalid requests and bodies over MaxBodySize get status 400, transport errors 502.
+type Gateway struct {
+	conf *GatewayConfig
+}
+
+// NewGateway create a JSON gateway of the proxy
+func NewGateway(c *GatewayConfig) (*Gateway, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &Gateway{conf: c}, nil
+}
+
+// ServeHTTP implements http.Handler
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeGatewayError(w, http.StatusMethodNotAllowed, &Fault{Message: "method must be POST"})
+		return
+	}
+
+	m := strings.TrimPrefix(r.URL.Path, "/")
+	if len(m) == 0 || strings.Contains(m, "/") {
+		writeGatewayError(w, http.StatusNotFound, &Fault{Message: fmt.Sprintf("invalid method %q", m)})
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, g.conf.MaxBodySize)
+	args, err := g.readArgs(r, m)
+	if err != nil {
+		writeGatewayError(w, http.StatusBadRequest, &Fault{Message: err.Error()})
+		return
+	}
+
+	ans, err := g.conf.Proxy.InvokeContext(r.Context(), m, args...)
+	if err != nil {
+		if f, ok := err.(*Fault); ok {
+			status := http.StatusInternalServerError
+			if f.Code == "NoSuchMethodException" || f.Code == "NoSuchObjectException" {
+				status = http.StatusNotFound
+			}
+			writeGatewayError(w, status, f)
+			return
+		}
+		writeGatewayError(w, http.StatusBadGateway, &Fault{Message: err.Error()})
+		return
+	}
+
+	var reply interface{}
+	if len(ans) == 1 {
+		reply = PlainValue(ans[0])
+	} else {
+		reply = PlainValue(ans)
+	}
+
+	p, err := json.Marshal(reply)
+	if err != nil {
+		writeGatewayError(w, http.StatusInternalServerError, &Fault{Message: err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(p)
+}
+
+// readArgs read JSON array of arguments and apply hints of method m
+func (g *Gateway) readArgs(r *http.Request, m string) ([]interface{}, error) {
+	p, err := ioutil.ReadAll(r.Body)
+	if err != nil && int64(len(p)) >= g.conf.MaxBodySize {
+		return nil, fmt.Errorf("request body exceeds %d bytes", g.conf.MaxBodySize)
+	}
+	if err != nil {
+		return nil, err
+	}
+	p = bytes.TrimSpace(p)
+	if len(p) == 0 {
+		return nil, nil
+	}
+	if p[0] != '[' {
+		return nil, fmt.Errorf("arguments must be a JSON array")
+	}
+
+	v, err := FromJSON(p)
+	if err != nil {
+		return nil, err
+	}
+	args := v.(TypedList).Items
+
+	hints := g.conf.Hints[m]
+	for i := range args {
+		if i >= len(hints) {
+			break
+		}
+		if args[i], err = applyHint(args[i], hints[i]); err != nil {
+			return nil, fmt.Errorf("argument %d: %v", i+1, err)
+		}
+	}
+	return args, nil
+}
+
+// applyHint convert v to the hessian type of hint
+func applyHint(v interface{}, hint string) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	switch hint {
+	case "", "object":
+		return v, nil
+	case "boolean":
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	case "string":
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	case "int":
+		switch n := v.(type) {
+		case int32:
+			return n, nil
+		case int64:
+			if n >= math.MinInt32 && n <= math.MaxInt32 {
+				return int32(n), nil
+			}
+		}
+	case "long":
+		switch n := v.(type) {
+		case int32:
+			return int64(n), nil
+		case int64:
+			return n, nil
+		case string:
+			if i, err := strconv.ParseInt(n, 10, 64); err == nil {
+				return i, nil
+			}
+		}
+	case "double":
+		switch n := v.(type) {
+		case int32:
+			return float64(n), nil
+		case int64:
+			return float64(n), nil
+		case float64:
+			return n, nil
+		}
+	case "date":
+		switch d := v.(type) {
+		case time.Time:
+			return d, nil
+		case int32:
+			return time.Unix(int64(d)/1e3, int64(d)%1e3*1e6), nil
+		case int64:
+			return time.Unix(d/1e3, d%1e3*1e6), nil
+		case string:
+			if t, err := time.Parse(time.RFC3339Nano, d); err == nil {
+				return t, nil
+			}
+		}
+	case "binary":
+		switch b := v.(type) {
+		case []byte:
+			return b, nil
+		case string:
+			if p, err := base64.StdEncoding.DecodeString(b); err == nil {
+				return p, nil
+			}
+		}
+	default:
+		switch c := v.(type) {
+		case TypedMap:
+			if len(c.Type) == 0 {
+				c.Type = hint
+			}
+			return c, nil
+		case TypedList:
+			if len(c.Type) == 0 {
+				c.Type = hint
+			}
+			if strings.HasPrefix(hint, "[") {
+				items := make([]interface{}, len(c.Items))
+				for i := range c.Items {
+					item, err := applyHint(c.Items[i], hint[1:])
+					if err != nil {
+						return nil, fmt.Errorf("item %d: %v", i, err)
+					}
+					items[i] = item
+				}
+				c.Items = items
+			}
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("can not convert %T to %s", v, hint)
+}
+
+// writeGatewayError write fault as JSON
+func writeGatewayError(w http.ResponseWriter, status int, f *Fault) {
+	body := map[string]interface{}{"message": f.Message}
+	if len(f.Code) > 0 {
+		body["code"] = f.Code
+	}
+	if f.Detail != nil {
+		body["detail"] = PlainValue(f.Detail)
+	}
+	p, err := json.Marshal(body)
+	if err != nil {
+		delete(body, "detail")
+		p, _ = json.Marshal(body)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(p)
+}
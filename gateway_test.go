@@ -0,0 +1,211 @@
+package hessian
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGatewayConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    *GatewayConfig
+		wantErr bool
+	}{
+		{name: "ok", conf: &GatewayConfig{Proxy: &Proxy{}}, wantErr: false},
+		{name: "no proxy", conf: &GatewayConfig{}, wantErr: true},
+		{name: "negative MaxBodySize", conf: &GatewayConfig{Proxy: &Proxy{}, MaxBodySize: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.conf.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("GatewayConfig.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && tt.conf.MaxBodySize != 1<<20 {
+				t.Errorf("GatewayConfig.Validate() MaxBodySize = %v, want %v", tt.conf.MaxBodySize, 1<<20)
+			}
+		})
+	}
+}
+
+// faultReply r x01 x00 f code message z
+func faultReply(code, message string) []byte {
+	o := NewSerializerV1()
+	o.WriteFault(code, message, nil)
+	return o.buf.Bytes()
+}
+
+func TestGateway_ServeHTTP(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := ioutil.ReadAll(r.Body)
+		switch {
+		case bytes.Contains(p, []byte("getUser")):
+			// the long hint of the argument
+			if !bytes.Contains(p, []byte{'L', 0, 0, 0, 0, 0, 0, 0, 1}) {
+				w.Write(faultReply("ProtocolException", "want long"))
+				return
+			}
+			w.Write([]byte{
+				'r', 0x01, 0x00,
+				'M', 't', 0x00, 0x00,
+				'S', 0x00, 0x04, 'n', 'a', 'm', 'e', 'S', 0x00, 0x07, 'g', 'g', 'w', 'h', 'i', 't', 'e',
+				'z',
+				'z',
+			})
+		case bytes.Contains(p, []byte("boom")):
+			w.Write(faultReply("ServiceException", "boom"))
+		default:
+			w.Write(faultReply("NoSuchMethodException", "no such method"))
+		}
+	}))
+	defer upstream.Close()
+
+	proxy, err := NewProxy(&ProxyConfig{Version: V1, URL: upstream.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gw, err := NewGateway(&GatewayConfig{
+		Proxy: proxy,
+		Hints: map[string][]string{"getUser": {"long"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	downProxy, _ := NewProxy(&ProxyConfig{Version: V1, URL: down.URL})
+	downGw, _ := NewGateway(&GatewayConfig{Proxy: downProxy})
+	smallGw, _ := NewGateway(&GatewayConfig{Proxy: proxy, MaxBodySize: 8})
+
+	tests := []struct {
+		name       string
+		gw         *Gateway
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "ok", gw: gw, method: http.MethodPost, path: "/getUser", body: `[1]`, wantStatus: 200, wantBody: `{"name":"ggwhite"}`},
+		{name: "typed argument", gw: gw, method: http.MethodPost, path: "/getUser", body: `[{"$long": 1}]`, wantStatus: 200, wantBody: `{"name":"ggwhite"}`},
+		{name: "no such method", gw: gw, method: http.MethodPost, path: "/foo", body: ``, wantStatus: 404, wantBody: `{"code":"NoSuchMethodException","message":"no such method"}`},
+		{name: "service fault", gw: gw, method: http.MethodPost, path: "/boom", body: `[]`, wantStatus: 500, wantBody: `{"code":"ServiceException","message":"boom"}`},
+		{name: "GET", gw: gw, method: http.MethodGet, path: "/getUser", wantStatus: 405, wantBody: `{"message":"method must be POST"}`},
+		{name: "empty method", gw: gw, method: http.MethodPost, path: "/", wantStatus: 404, wantBody: `{"message":"invalid method \"\""}`},
+		{name: "not array", gw: gw, method: http.MethodPost, path: "/getUser", body: `{}`, wantStatus: 400, wantBody: `{"message":"arguments must be a JSON array"}`},
+		{name: "invalid JSON", gw: gw, method: http.MethodPost, path: "/getUser", body: `[`, wantStatus: 400},
+		{name: "hint mismatch", gw: gw, method: http.MethodPost, path: "/getUser", body: `[true]`, wantStatus: 400, wantBody: `{"message":"argument 1: can not convert bool to long"}`},
+		{name: "body at limit", gw: smallGw, method: http.MethodPost, path: "/boom", body: `[      ]`, wantStatus: 500},
+		{name: "body too large", gw: smallGw, method: http.MethodPost, path: "/boom", body: `[1, 2, 3]`, wantStatus: 400, wantBody: `{"message":"request body exceeds 8 bytes"}`},
+		{name: "upstream down", gw: downGw, method: http.MethodPost, path: "/getUser", body: `[]`, wantStatus: 502},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.gw.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			if w.Code != tt.wantStatus {
+				t.Errorf("Gateway.ServeHTTP() status = %v, want %v, body %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if len(tt.wantBody) > 0 && w.Body.String() != tt.wantBody {
+				t.Errorf("Gateway.ServeHTTP() body = %s, want %s", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestApplyHint(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       interface{}
+		hint    string
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "nil", v: nil, hint: "long", want: nil},
+		{name: "object", v: int32(1), hint: "object", want: int32(1)},
+		{name: "int", v: int64(1), hint: "int", want: int32(1)},
+		{name: "int overflow", v: int64(1 << 40), hint: "int", wantErr: true},
+		{name: "long", v: int32(1), hint: "long", want: int64(1)},
+		{name: "long string", v: "9007199254740993", hint: "long", want: int64(9007199254740993)},
+		{name: "double", v: int32(2), hint: "double", want: float64(2)},
+		{name: "string", v: int32(2), hint: "string", wantErr: true},
+		{name: "boolean", v: true, hint: "boolean", want: true},
+		{name: "date", v: "2019-04-18T16:20:52Z", hint: "date", want: time.Date(2019, 4, 18, 16, 20, 52, 0, time.UTC)},
+		{name: "binary", v: "aGVsbG8=", hint: "binary", want: []byte("hello")},
+		{name: "class", v: TypedMap{}, hint: "lab.ggw.User", want: TypedMap{Type: "lab.ggw.User"}},
+		{name: "class keep type", v: TypedMap{Type: "a.B"}, hint: "lab.ggw.User", want: TypedMap{Type: "a.B"}},
+		{
+			name: "list",
+			v:    TypedList{Items: []interface{}{int32(1)}},
+			hint: "[long",
+			want: TypedList{Type: "[long", Items: []interface{}{int64(1)}},
+		},
+		{name: "list item mismatch", v: TypedList{Items: []interface{}{"a"}}, hint: "[long", wantErr: true},
+		{name: "class mismatch", v: int32(1), hint: "lab.ggw.User", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyHint(tt.v, tt.hint)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("applyHint() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tm, ok := got.(time.Time); ok {
+				if !tm.Equal(tt.want.(time.Time)) {
+					t.Errorf("applyHint() = %v, want %v", got, tt.want)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyHint() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGateway_Concurrent(t *testing.T) {
+	// the upstream reply only when both calls arrive, calls through the gateway must not wait for each other
+	var arrived sync.WaitGroup
+	arrived.Add(2)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived.Done()
+		arrived.Wait()
+		w.Write([]byte{'r', 0x01, 0x00, 'T', 'z'})
+	}))
+	defer upstream.Close()
+
+	proxy, err := NewProxy(&ProxyConfig{Version: V1, URL: upstream.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gw, err := NewGateway(&GatewayConfig{Proxy: proxy})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan string, 2)
+	for k := 0; k < 2; k++ {
+		go func() {
+			w := httptest.NewRecorder()
+			gw.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/ok", strings.NewReader("[]")))
+			done <- w.Body.String()
+		}()
+	}
+	for k := 0; k < 2; k++ {
+		select {
+		case got := <-done:
+			if strings.TrimSpace(got) != "true" {
+				t.Errorf("Gateway.ServeHTTP() = %s, want true", got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Gateway.ServeHTTP() calls are not concurrent")
+		}
+	}
+}
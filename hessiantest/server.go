@@ -0,0 +1,195 @@
+// Package hessiantest provide an in-process hessian server for testing hessian clients.
+//
+//	s := hessiantest.NewServer()
+//	defer s.Close()
+//
+//	s.Reply("str", "Hello")
+//	s.Fault("objI", "ServiceException", "no user")
+//	s.Handle("add", func(args []interface{}) (interface{}, error) {
+//		return args[0].(int64) + args[1].(int64), nil
+//	})
+//
+//	proxy, _ := hessian.NewProxy(&hessian.ProxyConfig{Version: hessian.V1, URL: s.URL})
+package hessiantest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+
+	hessian "github.com/ggwhite/go-hessian"
+)
+
+// HandlerFunc handle a call by its decoded arguments, a returned *hessian.Fault is replied as is,
+// other errors are replied as ServiceException
+type HandlerFunc func(args []interface{}) (interface{}, error)
+
+// Call a call received by Server
+type Call struct {
+	// Method the method name on the wire, mangled names are not stripped
+	Method string
+	Args   []interface{}
+	Header http.Header
+}
+
+// Server a fake hessian 1.0 server on an httptest.Server, calls of unregistered methods are
+// replied as NoSuchMethodException. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	handlers map[string]HandlerFunc
+	typeMap  map[string]reflect.Type
+	calls    []Call
+}
+
+// NewServer create and start a server, the caller should call Close when finished
+func NewServer() *Server {
+	s := &Server{
+		handlers: make(map[string]HandlerFunc),
+		typeMap:  make(map[string]reflect.Type),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Handle register handler h of method m
+func (s *Server) Handle(m string, h HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[m] = h
+}
+
+// Reply register a canned reply v of method m
+func (s *Server) Reply(m string, v interface{}) {
+	s.Handle(m, func([]interface{}) (interface{}, error) {
+		return v, nil
+	})
+}
+
+// Fault register a canned fault of method m
+func (s *Server) Fault(m, code, message string) {
+	s.Handle(m, func([]interface{}) (interface{}, error) {
+		return nil, &hessian.Fault{Code: code, Message: message}
+	})
+}
+
+// RegisterType input a type with Package field, arguments of its class are decoded to the type
+func (s *Server) RegisterType(t reflect.Type) error {
+	pkg := hessian.PackageOf(t)
+	if len(pkg) == 0 {
+		return fmt.Errorf("input type is without Package field")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.typeMap[pkg] = t
+	return nil
+}
+
+// Calls return calls received so far, in order
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// CallsOf return calls of method m received so far, in order
+func (s *Server) CallsOf(m string) []Call {
+	var ans []Call
+	for _, c := range s.Calls() {
+		if c.Method == m {
+			ans = append(ans, c)
+		}
+	}
+	return ans
+}
+
+// Reset forget received calls, registered handlers are kept
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = nil
+}
+
+// serveHTTP decode the call, record it and write the reply of its handler
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Hessian requires POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	m, args, err := s.readCall(r)
+	if err != nil {
+		writeFault(w, &hessian.Fault{Code: "ProtocolException", Message: err.Error()})
+		return
+	}
+
+	// copy the header, a recorded call does not share it with the request
+	header := make(http.Header, len(r.Header))
+	for k, v := range r.Header {
+		header[k] = append([]string(nil), v...)
+	}
+
+	s.mu.Lock()
+	s.calls = append(s.calls, Call{Method: m, Args: args, Header: header})
+	h, ok := s.handlers[m]
+	s.mu.Unlock()
+
+	if !ok {
+		writeFault(w, &hessian.Fault{Code: "NoSuchMethodException", Message: fmt.Sprintf("The service has no method named: %s", m)})
+		return
+	}
+
+	v, err := h(args)
+	if err != nil {
+		f, ok := err.(*hessian.Fault)
+		if !ok {
+			f = &hessian.Fault{Code: "ServiceException", Message: err.Error()}
+		}
+		writeFault(w, f)
+		return
+	}
+
+	o := hessian.NewSerializerV1()
+	if err := o.WriteReply(v, nil); err != nil {
+		writeFault(w, &hessian.Fault{Code: "ServiceException", Message: err.Error()})
+		return
+	}
+	write(w, o)
+}
+
+// readCall decode the call of r as hessian.Server does, arguments of registered classes are
+// decoded to their types
+func (s *Server) readCall(r *http.Request) (string, []interface{}, error) {
+	s.mu.Lock()
+	typeMap := make(map[string]reflect.Type, len(s.typeMap))
+	for k, v := range s.typeMap {
+		typeMap[k] = v
+	}
+	s.mu.Unlock()
+
+	i := hessian.NewDeserializerV1()
+	i.SetTypeMap(typeMap)
+	return i.ReadRequest(r)
+}
+
+// writeFault write fault f, the detail is dropped if it can not be written
+func writeFault(w http.ResponseWriter, f *hessian.Fault) {
+	o := hessian.NewSerializerV1()
+	if err := o.WriteFault(f.Code, f.Message, f.Detail); err != nil {
+		o.Flush()
+		o.WriteFault(f.Code, f.Message, nil)
+	}
+	write(w, o)
+}
+
+// write write content of o as hessian response
+func write(w http.ResponseWriter, o *hessian.SerializerV1) {
+	w.Header().Set("Content-Type", "x-application/hessian")
+	io.Copy(w, o.Reader())
+}
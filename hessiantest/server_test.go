@@ -0,0 +1,189 @@
+package hessiantest
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	hessian "github.com/ggwhite/go-hessian"
+)
+
+type user struct {
+	hessian.Package `hessian:"lab.ggw.User"`
+	Name            string `hessian:"name"`
+}
+
+func TestServer(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.Reply("str", "Hello")
+	s.Fault("objI", "NoSuchObjectException", "no user")
+	s.Handle("add", func(args []interface{}) (interface{}, error) {
+		return args[0].(int64) + args[1].(int64), nil
+	})
+	s.Handle("boom", func(args []interface{}) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+
+	proxy, err := hessian.NewProxy(&hessian.ProxyConfig{
+		Version:     hessian.V1,
+		URL:         s.URL,
+		Header:      http.Header{"X-Tenant": []string{"ggw"}},
+		Compression: hessian.CompressGzip,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		m        string
+		args     []interface{}
+		want     []interface{}
+		wantCode string
+	}{
+		{name: "canned reply", m: "str", want: []interface{}{"Hello"}},
+		{name: "handler", m: "add", args: []interface{}{int64(1), int64(2)}, want: []interface{}{int64(3)}},
+		{name: "canned fault", m: "objI", args: []interface{}{"ggwhite"}, wantCode: "NoSuchObjectException"},
+		{name: "handler error", m: "boom", wantCode: "ServiceException"},
+		{name: "no such method", m: "delete", wantCode: "NoSuchMethodException"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := proxy.Invoke(tt.m, tt.args...)
+			if len(tt.wantCode) > 0 {
+				if f, ok := err.(*hessian.Fault); !ok || f.Code != tt.wantCode {
+					t.Errorf("Proxy.Invoke() error = %v, want fault %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Proxy.Invoke() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Proxy.Invoke() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	calls := s.Calls()
+	if len(calls) != len(tests) {
+		t.Fatalf("Server.Calls() len = %d, want %d", len(calls), len(tests))
+	}
+	for i, c := range calls {
+		if c.Method != tests[i].m {
+			t.Errorf("Server.Calls()[%d].Method = %s, want %s", i, c.Method, tests[i].m)
+		}
+		if c.Header.Get("X-Tenant") != "ggw" {
+			t.Errorf("Server.Calls()[%d].Header = %v, want X-Tenant", i, c.Header)
+		}
+	}
+
+	add := s.CallsOf("add")
+	if len(add) != 1 || !reflect.DeepEqual(add[0].Args, []interface{}{int64(1), int64(2)}) {
+		t.Errorf("Server.CallsOf() = %#v", add)
+	}
+
+	s.Reset()
+	if len(s.Calls()) != 0 {
+		t.Errorf("Server.Reset() calls = %v, want empty", s.Calls())
+	}
+}
+
+func TestServer_RegisterType(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.Reply("save", nil)
+
+	if err := s.RegisterType(reflect.TypeOf(struct{ Name string }{})); err == nil {
+		t.Errorf("Server.RegisterType() error = nil, want error")
+	}
+	if err := s.RegisterType(reflect.TypeOf(&user{})); err != nil {
+		t.Fatalf("Server.RegisterType() error = %v", err)
+	}
+
+	proxy, err := hessian.NewProxy(&hessian.ProxyConfig{Version: hessian.V1, URL: s.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := proxy.Invoke("save", &user{Name: "ggwhite"}); err != nil {
+		t.Fatalf("Proxy.Invoke() error = %v", err)
+	}
+
+	want := []interface{}{&user{Name: "ggwhite"}}
+	if got := s.Calls()[0].Args; !reflect.DeepEqual(got, want) {
+		t.Errorf("Server.Calls()[0].Args = %+v, want %+v", got[0], want[0])
+	}
+}
+
+func TestServer_Invalid(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	resp, err := http.Get(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+
+	resp, err = http.Post(s.URL, "x-application/hessian", strings.NewReader("c\x01\x00m\x00\x09getU"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	d := hessian.NewDeserializerV1()
+	d.Reset(resp.Body)
+	if _, err := d.Read(); err == nil || !strings.Contains(err.Error(), "ProtocolException") {
+		t.Errorf("malformed call error = %v, want ProtocolException", err)
+	}
+	if len(s.Calls()) != 0 {
+		t.Errorf("Server.Calls() = %v, want empty", s.Calls())
+	}
+}
+
+func TestServer_Request(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.Reply("str", "Hello")
+
+	call := "c\x01\x00m\x00\x03strz"
+	var raw bytes.Buffer
+	fw, _ := flate.NewWriter(&raw, flate.DefaultCompression)
+	fw.Write([]byte(call))
+	fw.Close()
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+	}{
+		{name: "plain", body: []byte(call)},
+		{name: "headers", body: []byte("c\x01\x00H\x00\x01aNH\x00\x01bTm\x00\x03strz")},
+		{name: "raw deflate", encoding: "deflate", body: raw.Bytes()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(tt.body))
+			req.Header.Set("Content-Encoding", tt.encoding)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			d := hessian.NewDeserializerV1()
+			d.Reset(resp.Body)
+			if got, err := d.Read(); err != nil || !reflect.DeepEqual(got, []interface{}{"Hello"}) {
+				t.Errorf("reply = %v, %v, want [Hello]", got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package hessian
+
+import (
+	"math"
+	"reflect"
+)
+
+type intPolicy int
+
+// Integer policies, how go int and uint are written. The sized kinds are always written as their
+// java types: int8, int16, int32, uint8 and uint16 as int, int64, uint32 and uint64 as long.
+const (
+	// IntStrict write int, an *UnsupportedValueError if the value overflows int
+	IntStrict intPolicy = iota
+	// IntAuto write int, or long if the value overflows int
+	IntAuto
+	// IntAlwaysLong always write long
+	IntAlwaysLong
+)
+
+// intsAsLong true if go int or uint rv, or all of them in list rv, are written as long by policy p.
+// IntAuto write a whole list as long if any of its values overflows int.
+func intsAsLong(p intPolicy, rv reflect.Value) bool {
+	switch p {
+	case IntAlwaysLong:
+		return true
+	case IntAuto:
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return !fitsInt(rv)
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if !fitsInt(rv.Index(i)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fitsInt true if go int or uint rv fits in java int
+func fitsInt(rv reflect.Value) bool {
+	if rv.Kind() == reflect.Uint {
+		return rv.Uint() <= math.MaxInt32
+	}
+	return rv.Int() >= math.MinInt32 && rv.Int() <= math.MaxInt32
+}
+
+// writeInteger write go int or uint rv as long or int, an int which overflows is an error
+func (o *SerializerV1) writeInteger(rv reflect.Value, long bool) error {
+	var n int64
+	if rv.Kind() == reflect.Uint {
+		if rv.Uint() > math.MaxInt64 {
+			return &UnsupportedValueError{Value: rv.Interface(), Reason: "overflows long"}
+		}
+		n = int64(rv.Uint())
+	} else {
+		n = rv.Int()
+	}
+
+	if long {
+		return o.WriteLong(n)
+	}
+	if !fitsInt(rv) {
+		return &UnsupportedValueError{Value: rv.Interface(), Reason: "overflows int"}
+	}
+	return o.WriteInt(int32(n))
+}
+
+// SetIntPolicy set how go int and uint are written, default IntStrict
+func (o *SerializerV1) SetIntPolicy(p intPolicy) {
+	o.intPolicy = p
+}
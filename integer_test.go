@@ -0,0 +1,189 @@
+package hessian
+
+import (
+	"fmt"
+	"math"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSerializerV1_SetIntPolicy(t *testing.T) {
+	type count int
+	tests := []struct {
+		name    string
+		policy  intPolicy
+		arg     interface{}
+		wantBuf []byte
+		wantErr bool
+	}{
+		{name: "strict int", policy: IntStrict, arg: 1, wantBuf: []byte{'I', 0, 0, 0, 1}},
+		{name: "strict max int", policy: IntStrict, arg: math.MaxInt32, wantBuf: []byte{'I', 0x7f, 0xff, 0xff, 0xff}},
+		{name: "strict overflow", policy: IntStrict, arg: math.MaxInt32 + 1, wantErr: true},
+		{name: "strict underflow", policy: IntStrict, arg: math.MinInt32 - 1, wantErr: true},
+		{name: "strict uint overflow", policy: IntStrict, arg: uint(math.MaxInt32 + 1), wantErr: true},
+		{name: "auto int", policy: IntAuto, arg: -1, wantBuf: []byte{'I', 0xff, 0xff, 0xff, 0xff}},
+		{name: "auto overflow", policy: IntAuto, arg: math.MaxInt32 + 1, wantBuf: []byte{'L', 0, 0, 0, 0, 0x80, 0, 0, 0}},
+		{name: "auto uint overflow", policy: IntAuto, arg: uint(math.MaxInt32 + 1), wantBuf: []byte{'L', 0, 0, 0, 0, 0x80, 0, 0, 0}},
+		{name: "auto named", policy: IntAuto, arg: count(2), wantBuf: []byte{'I', 0, 0, 0, 2}},
+		{name: "always long", policy: IntAlwaysLong, arg: 1, wantBuf: []byte{'L', 0, 0, 0, 0, 0, 0, 0, 1}},
+		{name: "always long uint", policy: IntAlwaysLong, arg: uint(1), wantBuf: []byte{'L', 0, 0, 0, 0, 0, 0, 0, 1}},
+		{name: "always long uint overflow", policy: IntAlwaysLong, arg: uint(math.MaxUint64), wantErr: true},
+		{name: "sized kinds not affected", policy: IntAlwaysLong, arg: int32(1), wantBuf: []byte{'I', 0, 0, 0, 1}},
+		{name: "int64 not affected", policy: IntStrict, arg: int64(math.MaxInt32 + 1), wantBuf: []byte{'L', 0, 0, 0, 0, 0x80, 0, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewSerializerV1()
+			o.SetIntPolicy(tt.policy)
+			err := o.WriteObject(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SerializerV1.WriteObject() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if _, ok := err.(*UnsupportedValueError); tt.wantErr && !ok {
+				t.Errorf("SerializerV1.WriteObject() error = %T, want *UnsupportedValueError", err)
+			}
+			if b := o.buf.Bytes(); !tt.wantErr && !reflect.DeepEqual(b, tt.wantBuf) {
+				t.Errorf("SerializerV1.WriteObject() content is %v, wantBuf %v", b, tt.wantBuf)
+			}
+		})
+	}
+
+	// path of the overflowed value
+	err := NewSerializerV1().Call("add", 1, []int{0, math.MaxInt32 + 1})
+	if e, ok := err.(*UnsupportedValueError); !ok || e.Path != "args[1][1]" {
+		t.Errorf("SerializerV1.Call() error = %v, want overflow at args[1][1]", err)
+	}
+}
+
+func TestCodecConfig_IntPolicy(t *testing.T) {
+	codec, err := NewCodec(&CodecConfig{Version: V1, IntPolicy: IntAuto})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := codec.Marshal([]interface{}{1, 1 << 40})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// decoded back to int, narrowed with overflow check
+	var got []int
+	if err := codec.Unmarshal(p, &got); err != nil || !reflect.DeepEqual(got, []int{1, 1 << 40}) {
+		t.Errorf("Codec.Unmarshal() = %v, %v", got, err)
+	}
+	var small []int32
+	if err := codec.Unmarshal(p, &small); err == nil {
+		t.Errorf("Codec.Unmarshal() = %v, want overflow error", small)
+	}
+}
+
+type integerService struct{}
+
+func (integerService) Size() int { return 1 << 40 }
+
+func (integerService) Echo(n int64) int64 { return n }
+
+func TestServer_SetIntPolicy(t *testing.T) {
+	s, err := NewServer(integerService{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	if _, err := NewProxy(&ProxyConfig{Version: V1, URL: srv.URL, IntPolicy: intPolicy(9)}); err == nil {
+		t.Errorf("NewProxy() error = nil, want unknown IntPolicy")
+	}
+	proxy, err := NewProxy(&ProxyConfig{Version: V1, URL: srv.URL, IntPolicy: IntAlwaysLong})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reply can not be written as int
+	if _, err := proxy.Invoke("Size"); err == nil {
+		t.Errorf("Proxy.Invoke() error = nil, want fault")
+	}
+
+	s.SetIntPolicy(IntAuto)
+	if got, err := proxy.Invoke("Size"); err != nil || !reflect.DeepEqual(got, []interface{}{int64(1 << 40)}) {
+		t.Errorf("Proxy.Invoke() = %v, %v, want %v", got, err, int64(1<<40))
+	}
+
+	// go int argument written as long for java long parameter
+	if got, err := proxy.Invoke("Echo", 1); err != nil || !reflect.DeepEqual(got, []interface{}{int64(1)}) {
+		t.Errorf("Proxy.Invoke() = %v, %v, want %v", got, err, int64(1))
+	}
+}
+
+// intMoney go int written as string by Marshaler
+type intMoney int
+
+func (m intMoney) MarshalHessian(enc *Encoder) error {
+	return enc.Encode(fmt.Sprint(int(m)))
+}
+
+// intCode go uint written by class codec
+type intCode uint
+
+func TestSerializerV1_SetIntPolicy_List(t *testing.T) {
+	header := func(typ string, n byte) []byte {
+		return append(append([]byte{'V', 't', 0, byte(len(typ))}, typ...), 'l', 0, 0, 0, n)
+	}
+	i := func(n byte) []byte { return []byte{'I', 0, 0, 0, n} }
+	l := func(b ...byte) []byte { return append([]byte{'L', 0, 0, 0}, b...) }
+	list := func(parts ...[]byte) []byte {
+		var ans []byte
+		for _, p := range parts {
+			ans = append(ans, p...)
+		}
+		return append(ans, 'z')
+	}
+
+	classes := NewClassRegistry()
+	classes.Register(ClassCodec{
+		Class:  "lab.ggw.Code",
+		Type:   reflect.TypeOf(intCode(0)),
+		Encode: func(v interface{}) ([]MapEntry, error) { return []MapEntry{{Key: "v", Value: int32(v.(intCode))}}, nil },
+		Decode: func(m TypedMap) (interface{}, error) { return nil, nil },
+	})
+	code := append(append([]byte{'M', 't', 0, 12}, "lab.ggw.Code"...), 'S', 0, 1, 'v', 'I', 0, 0, 0, 7, 'z')
+
+	tests := []struct {
+		name    string
+		policy  intPolicy
+		arg     interface{}
+		wantBuf []byte
+		wantErr bool
+	}{
+		{name: "strict int", policy: IntStrict, arg: []int{1}, wantBuf: list(header("[int", 1), i(1))},
+		{name: "marshaler int", policy: IntStrict, arg: []intMoney{5}, wantBuf: list(header("[object", 1), []byte{'S', 0, 1, '5'})},
+		{name: "always long marshaler int", policy: IntAlwaysLong, arg: []intMoney{5}, wantBuf: list(header("[object", 1), []byte{'S', 0, 1, '5'})},
+		{name: "class uint", policy: IntAlwaysLong, arg: []intCode{7}, wantBuf: list(header("[object", 1), code)},
+		{name: "strict int overflow", policy: IntStrict, arg: []int{1, 1 << 32}, wantErr: true},
+		{name: "strict uint", policy: IntStrict, arg: []uint{1}, wantBuf: list(header("[int", 1), i(1))},
+		{name: "strict uint overflow", policy: IntStrict, arg: []uint{1 << 32}, wantErr: true},
+		{name: "auto int", policy: IntAuto, arg: []int{1}, wantBuf: list(header("[int", 1), i(1))},
+		{name: "auto int overflow", policy: IntAuto, arg: []int{1, 1 << 32}, wantBuf: list(header("[long", 2), l(0, 0, 0, 0, 1), l(1, 0, 0, 0, 0))},
+		{name: "auto uint", policy: IntAuto, arg: []uint{1}, wantBuf: list(header("[int", 1), i(1))},
+		{name: "auto uint overflow", policy: IntAuto, arg: [2]uint{1, 1 << 32}, wantBuf: list(header("[long", 2), l(0, 0, 0, 0, 1), l(1, 0, 0, 0, 0))},
+		{name: "always long int", policy: IntAlwaysLong, arg: []int{1, 1 << 32}, wantBuf: list(header("[long", 2), l(0, 0, 0, 0, 1), l(1, 0, 0, 0, 0))},
+		{name: "always long uint", policy: IntAlwaysLong, arg: []uint{1}, wantBuf: list(header("[long", 1), l(0, 0, 0, 0, 1))},
+		{name: "always long uint overflow", policy: IntAlwaysLong, arg: []uint{math.MaxUint64}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewSerializerV1()
+			o.SetIntPolicy(tt.policy)
+			o.SetClassRegistry(classes)
+			err := o.WriteObject(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SerializerV1.WriteObject() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if b := o.buf.Bytes(); !tt.wantErr && !reflect.DeepEqual(b, tt.wantBuf) {
+				t.Errorf("SerializerV1.WriteObject() content is %q, wantBuf %q", b, tt.wantBuf)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package hessian
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Decimal java.math.BigDecimal, the string of BigDecimal.toString, ex: "12.34", "1E+3"
+type Decimal string
+
+// Rat return d as big.Rat, ok is false if d is not a valid decimal
+func (d Decimal) Rat() (*big.Rat, bool) {
+	return new(big.Rat).SetString(string(d))
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, empty text is the zero Decimal
+func (d *Decimal) UnmarshalText(text []byte) error {
+	if len(text) > 0 {
+		if _, ok := Decimal(text).Rat(); !ok {
+			return fmt.Errorf("invalid Decimal %q", text)
+		}
+	}
+	*d = Decimal(text)
+	return nil
+}
+
+// UUID java.util.UUID, the 16 bytes in big endian order
+type UUID [16]byte
+
+// String return the canonical form of u, ex: 123e4567-e89b-12d3-a456-426614174000
+func (u UUID) String() string {
+	s := hex.EncodeToString(u[:])
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+// MarshalText implements encoding.TextMarshaler, the canonical form of u
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler by ParseUUID
+func (u *UUID) UnmarshalText(text []byte) error {
+	v, err := ParseUUID(string(text))
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
+
+// ParseUUID parse the canonical form of a UUID
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	p, err := hex.DecodeString(strings.Replace(s, "-", "", -1))
+	if err != nil || len(p) != len(u) || len(s) != 36 {
+		return u, fmt.Errorf("invalid UUID %q", s)
+	}
+	copy(u[:], p)
+	return u, nil
+}
+
+// Locale java.util.Locale, the string of Locale.toString, ex: "en_US"
+type Locale string
+
+// Currency java.util.Currency, the ISO 4217 code, ex: "USD"
+type Currency string
+
+// javaClasses codecs of java standard library types in the field layouts of java hessian
+var javaClasses = []ClassCodec{
+	{
+		// StringValueSerializer: value
+		Class: "java.math.BigDecimal",
+		Type:  reflect.TypeOf(Decimal("")),
+		Encode: func(v interface{}) ([]MapEntry, error) {
+			return []MapEntry{{Key: "value", Value: nullString(string(v.(Decimal)))}}, nil
+		},
+		Decode: func(m TypedMap) (interface{}, error) {
+			s, err := classField(m, "value", "")
+			return Decimal(s.(string)), err
+		},
+	},
+	{
+		// JavaSerializer: primitive fields, then mag
+		Class: "java.math.BigInteger",
+		Type:  reflect.TypeOf(&big.Int{}),
+		Encode: func(v interface{}) ([]MapEntry, error) {
+			n := v.(*big.Int)
+			return []MapEntry{
+				{Key: "signum", Value: int32(n.Sign())},
+				{Key: "bitCount", Value: int32(0)},
+				{Key: "bitLength", Value: int32(0)},
+				{Key: "lowestSetBit", Value: int32(0)},
+				{Key: "firstNonzeroIntNum", Value: int32(0)},
+				{Key: "mag", Value: bigIntMag(n)},
+			}, nil
+		},
+		Decode: func(m TypedMap) (interface{}, error) {
+			signum, err := classField(m, "signum", int32(0))
+			if err != nil {
+				return nil, err
+			}
+			mag, err := classField(m, "mag", []int32(nil))
+			if err != nil {
+				return nil, err
+			}
+			n := new(big.Int)
+			for _, w := range mag.([]int32) {
+				n.Lsh(n, 32)
+				n.Or(n, new(big.Int).SetUint64(uint64(uint32(w))))
+			}
+			if signum.(int32) < 0 {
+				n.Neg(n)
+			}
+			return n, nil
+		},
+	},
+	{
+		// JavaSerializer: mostSigBits, leastSigBits
+		Class: "java.util.UUID",
+		Type:  reflect.TypeOf(UUID{}),
+		Encode: func(v interface{}) ([]MapEntry, error) {
+			u := v.(UUID)
+			var most, least int64
+			for k := 0; k < 8; k++ {
+				most = most<<8 | int64(u[k])
+				least = least<<8 | int64(u[k+8])
+			}
+			return []MapEntry{{Key: "mostSigBits", Value: most}, {Key: "leastSigBits", Value: least}}, nil
+		},
+		Decode: func(m TypedMap) (interface{}, error) {
+			most, err := classField(m, "mostSigBits", int64(0))
+			if err != nil {
+				return nil, err
+			}
+			least, err := classField(m, "leastSigBits", int64(0))
+			if err != nil {
+				return nil, err
+			}
+			var u UUID
+			for k := 0; k < 8; k++ {
+				u[k] = byte(most.(int64) >> uint(56-8*k))
+				u[k+8] = byte(least.(int64) >> uint(56-8*k))
+			}
+			return u, nil
+		},
+	},
+	{
+		// LocaleSerializer write LocaleHandle: value
+		Class: "com.caucho.hessian.io.LocaleHandle",
+		Type:  reflect.TypeOf(Locale("")),
+		Encode: func(v interface{}) ([]MapEntry, error) {
+			return []MapEntry{{Key: "value", Value: string(v.(Locale))}}, nil
+		},
+		Decode: func(m TypedMap) (interface{}, error) {
+			s, err := classField(m, "value", "")
+			return Locale(s.(string)), err
+		},
+	},
+	{
+		// JavaSerializer: currencyCode
+		Class: "java.util.Currency",
+		Type:  reflect.TypeOf(Currency("")),
+		Encode: func(v interface{}) ([]MapEntry, error) {
+			return []MapEntry{{Key: "currencyCode", Value: nullString(string(v.(Currency)))}}, nil
+		},
+		Decode: func(m TypedMap) (interface{}, error) {
+			s, err := classField(m, "currencyCode", "")
+			return Currency(s.(string)), err
+		},
+	},
+	// SqlDateSerializer: value, time.Time is written as date so they are only decoded
+	{Class: "java.sql.Timestamp", Type: reflect.TypeOf(time.Time{}), Decode: decodeSQLDate},
+	{Class: "java.sql.Date", Type: reflect.TypeOf(time.Time{}), Decode: decodeSQLDate},
+	{Class: "java.sql.Time", Type: reflect.TypeOf(time.Time{}), Decode: decodeSQLDate},
+}
+
+func init() {
+	for _, c := range javaClasses {
+		DefaultClassRegistry.Register(c)
+	}
+}
+
+// classField get field of m converted to the type of zero, a missing field is zero
+func classField(m TypedMap, name string, zero interface{}) (interface{}, error) {
+	v, ok := m.Get(name)
+	if !ok || v == nil {
+		return zero, nil
+	}
+	rv, err := convertValue(v, reflect.TypeOf(zero))
+	if err != nil {
+		return zero, fmt.Errorf("field %s: %v", name, err)
+	}
+	return rv.Interface(), nil
+}
+
+// nullString nil for empty s, java parses the field and does not accept an empty string, null is
+// read as null
+func nullString(s string) interface{} {
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
+
+// bigIntMag magnitude of n in big endian int32 words without leading zero words
+func bigIntMag(n *big.Int) []int32 {
+	b := new(big.Int).Abs(n).Bytes()
+	mag := make([]int32, (len(b)+3)/4)
+	for k := range b {
+		// the last byte is the lowest byte of the last word
+		w := len(mag) - 1 - (len(b)-1-k)/4
+		mag[w] = mag[w]<<8 | int32(b[k])
+	}
+	return mag
+}
+
+// decodeSQLDate decode java.sql date types from value
+func decodeSQLDate(m TypedMap) (interface{}, error) {
+	return classField(m, "value", time.Time{})
+}
@@ -0,0 +1,230 @@
+package hessian
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestJavaClasses(t *testing.T) {
+	huge, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	uuid, _ := ParseUUID("123e4567-e89b-12d3-a456-426614174000")
+
+	tests := []struct {
+		name string
+		v    interface{}
+		want TypedMap
+	}{
+		{
+			name: "BigDecimal",
+			v:    Decimal("12.34"),
+			want: TypedMap{Type: "java.math.BigDecimal", Entries: []MapEntry{{Key: "value", Value: "12.34"}}},
+		},
+		{
+			name: "BigInteger",
+			v:    big.NewInt(1<<32 + 5),
+			want: TypedMap{Type: "java.math.BigInteger", Entries: []MapEntry{
+				{Key: "signum", Value: int32(1)},
+				{Key: "bitCount", Value: int32(0)},
+				{Key: "bitLength", Value: int32(0)},
+				{Key: "lowestSetBit", Value: int32(0)},
+				{Key: "firstNonzeroIntNum", Value: int32(0)},
+				{Key: "mag", Value: TypedList{Type: "[int", Items: []interface{}{int32(1), int32(5)}}},
+			}},
+		},
+		{
+			name: "BigInteger huge",
+			v:    huge,
+		},
+		{
+			name: "UUID",
+			v:    uuid,
+			want: TypedMap{Type: "java.util.UUID", Entries: []MapEntry{
+				{Key: "mostSigBits", Value: int64(0x123e4567e89b12d3)},
+				{Key: "leastSigBits", Value: int64(-6605018797301088256)},
+			}},
+		},
+		{
+			name: "BigDecimal zero",
+			v:    Decimal(""),
+			want: TypedMap{Type: "java.math.BigDecimal", Entries: []MapEntry{{Key: "value", Value: nil}}},
+		},
+		{
+			name: "Locale",
+			v:    Locale("en_US"),
+			want: TypedMap{Type: "com.caucho.hessian.io.LocaleHandle", Entries: []MapEntry{{Key: "value", Value: "en_US"}}},
+		},
+		{
+			name: "Currency",
+			v:    Currency("USD"),
+			want: TypedMap{Type: "java.util.Currency", Entries: []MapEntry{{Key: "currencyCode", Value: "USD"}}},
+		},
+		{
+			name: "Currency zero",
+			v:    Currency(""),
+			want: TypedMap{Type: "java.util.Currency", Entries: []MapEntry{{Key: "currencyCode", Value: nil}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(tt.want.Type) > 0 {
+				i := NewDeserializerV1()
+				i.SetKeepTypes(true)
+				got, _, err := i.ReadValueAt(p, 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Marshal() = %#v, want %#v", got, tt.want)
+				}
+			}
+
+			var v interface{}
+			if err := Unmarshal(p, &v); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(v, tt.v) {
+				t.Errorf("Unmarshal() = %#v, want %#v", v, tt.v)
+			}
+		})
+	}
+}
+
+func TestJavaClasses_SQLDate(t *testing.T) {
+	date := time.Date(2019, 4, 18, 16, 20, 52, 123e6, time.Local)
+	for _, class := range []string{"java.sql.Timestamp", "java.sql.Date", "java.sql.Time"} {
+		o := NewSerializerV1()
+		o.WriteTypedMap(TypedMap{Type: class, Entries: []MapEntry{{Key: "value", Value: date}}})
+
+		var got time.Time
+		if err := Unmarshal(o.buf.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(date) {
+			t.Errorf("Unmarshal() %s = %v, want %v", class, got, date)
+		}
+	}
+
+	// time.Time is still written as date
+	if p, _ := Marshal(date); p[0] != 'd' {
+		t.Errorf("Marshal() = %q, want date", p)
+	}
+}
+
+func TestJavaClasses_Struct(t *testing.T) {
+	type account struct {
+		Package  `hessian:"lab.ggw.Account"`
+		ID       UUID     `hessian:"id"`
+		Balance  Decimal  `hessian:"balance"`
+		Currency Currency `hessian:"currency"`
+		Big      *big.Int `hessian:"big"`
+		Limit    *Decimal `hessian:"limit"`
+		Owner    *UUID    `hessian:"owner"`
+	}
+	limit := Decimal("100")
+	in := account{ID: UUID{1}, Balance: "1.5", Currency: "TWD", Big: big.NewInt(-1), Limit: &limit}
+
+	p, err := Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got account
+	if err := Unmarshal(p, &got); err != nil {
+		t.Fatal(err)
+	}
+	in.Package = ""
+	if !reflect.DeepEqual(got, in) {
+		t.Errorf("Unmarshal() = %#v, want %#v", got, in)
+	}
+}
+
+func TestBigIntMag(t *testing.T) {
+	tests := []struct {
+		name string
+		n    *big.Int
+		want []int32
+	}{
+		{name: "zero", n: big.NewInt(0), want: []int32{}},
+		{name: "small", n: big.NewInt(-255), want: []int32{255}},
+		{name: "two words", n: big.NewInt(1<<32 + 1), want: []int32{1, 1}},
+		{name: "high bit", n: big.NewInt(0xffffffff), want: []int32{-1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bigIntMag(tt.n); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bigIntMag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUUID(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		wantErr bool
+	}{
+		{name: "ok", s: "123e4567-e89b-12d3-a456-426614174000", wantErr: false},
+		{name: "short", s: "123e4567", wantErr: true},
+		{name: "not hex", s: "123e4567-e89b-12d3-a456-42661417400x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := ParseUUID(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseUUID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && u.String() != tt.s {
+				t.Errorf("UUID.String() = %v, want %v", u.String(), tt.s)
+			}
+		})
+	}
+}
+
+func TestDecimal_Rat(t *testing.T) {
+	r, ok := Decimal("1E+3").Rat()
+	if !ok || r.Cmp(big.NewRat(1000, 1)) != 0 {
+		t.Errorf("Decimal.Rat() = %v, %v, want 1000", r, ok)
+	}
+	if _, ok := Decimal("x").Rat(); ok {
+		t.Errorf("Decimal.Rat() ok = true, want false")
+	}
+}
+
+func TestJavaClasses_Text(t *testing.T) {
+	uuid, _ := ParseUUID("123e4567-e89b-12d3-a456-426614174000")
+	v := struct {
+		ID      UUID
+		Balance Decimal
+	}{ID: uuid, Balance: "12.34"}
+
+	p, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"ID":"123e4567-e89b-12d3-a456-426614174000","Balance":"12.34"}`; string(p) != want {
+		t.Errorf("json.Marshal() = %s, want %s", p, want)
+	}
+
+	var got struct {
+		ID      UUID
+		Balance Decimal
+	}
+	if err := json.Unmarshal(p, &got); err != nil || !reflect.DeepEqual(got, v) {
+		t.Errorf("json.Unmarshal() = %v, %v, want %v", got, err, v)
+	}
+
+	for _, in := range []string{`{"ID":"123e4567"}`, `{"Balance":"x"}`} {
+		if err := json.Unmarshal([]byte(in), &got); err == nil {
+			t.Errorf("json.Unmarshal(%s) error = nil, want error", in)
+		}
+	}
+}
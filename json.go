@@ -0,0 +1,610 @@
+package hessian
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ToJSON convert a hessian value to JSON keeping hessian type information, FromJSON convert it
+// back to the value which SerializerV1 write to the same bytes.
+//
+// Values read by DeserializerV1 with SetKeepTypes(true) convert without loss:
+//
+//	int                      1
+//	long                     {"$long": 1}
+//	double                   {"$double": 1.5}, NaN and Inf are strings
+//	date                     {"$date": "2019-04-18T16:20:52Z"}
+//	binary                   {"$binary": "aGVsbG8="}
+//	map with string keys     {"$class": "lab.ggw.User", "name": "ggwhite"}, $class is omitted for untyped map
+//	map with other keys      {"$class": "lab.ggw.User", "$map": [[1, "one"], [2, "two"]]}
+//	list                     {"$list": [1, 2], "$type": "[int"}, untyped list is a JSON array
+//
+// Go maps are written with sorted keys, slices with the list type SerializerV1 use. Marshaler and
+// types of DefaultClassRegistry are written in the form SerializerV1 write them, ex: Decimal as
+// {"$class": "java.math.BigDecimal", "value": "1.5"}. json.Marshaler and encoding.TextMarshaler are
+// not used, PlainValue keep them for encoding/json.
+func ToJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// PlainValue convert a decoded hessian value to a value encoding/json can marshal, without hessian
+// type information. Typed maps and structs become objects keyed by hessian tag, map keys are
+// formatted as text, json.Marshaler and encoding.TextMarshaler are kept for encoding/json, NaN and
+// Inf are strings.
+func PlainValue(v interface{}) interface{} {
+	return plainValue(reflect.ValueOf(v))
+}
+
+// plainValue PlainValue of v
+func plainValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch x := v.Interface().(type) {
+	case []byte, json.Marshaler, encoding.TextMarshaler:
+		if rv := reflect.ValueOf(x); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return nil
+		}
+		return x
+	case TypedMap:
+		m := make(map[string]interface{}, len(x.Entries))
+		for _, e := range x.Entries {
+			m[plainKey(e.Key)] = plainValue(reflect.ValueOf(e.Value))
+		}
+		return m
+	case TypedList:
+		return plainValue(reflect.ValueOf(x.Items))
+	}
+
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		// NaN and Inf are not valid JSON numbers
+		if f := v.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return plainValue(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		ans := make([]interface{}, v.Len())
+		for i := range ans {
+			ans[i] = plainValue(v.Index(i))
+		}
+		return ans
+	case reflect.Map:
+		ans := make(map[string]interface{}, v.Len())
+		for _, k := range v.MapKeys() {
+			ans[plainKey(k.Interface())] = plainValue(v.MapIndex(k))
+		}
+		return ans
+	case reflect.Struct:
+		t := v.Type()
+		ans := make(map[string]interface{}, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if len(f.PkgPath) > 0 || f.Type == reflect.TypeOf(Package("")) {
+				continue
+			}
+			name := f.Tag.Get(tagName)
+			if len(name) == 0 {
+				name = f.Name
+			}
+			ans[name] = plainValue(v.Field(i))
+		}
+		return ans
+	}
+	return v.Interface()
+}
+
+// plainKey format map key k as JSON object key
+func plainKey(k interface{}) string {
+	switch k := k.(type) {
+	case string:
+		return k
+	case nil:
+		return "null"
+	case []byte:
+		return base64.StdEncoding.EncodeToString(k)
+	case encoding.TextMarshaler:
+		if p, err := k.MarshalText(); err == nil {
+			return string(p)
+		}
+	}
+	return fmt.Sprint(k)
+}
+
+// writeJSON write JSON of v to buf
+func writeJSON(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("null")
+		return nil
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+		return nil
+	case string:
+		writeJSONString(buf, v)
+		return nil
+	case int32:
+		buf.WriteString(strconv.FormatInt(int64(v), 10))
+		return nil
+	case int64:
+		fmt.Fprintf(buf, `{"$long":%d}`, v)
+		return nil
+	case float32:
+		writeJSONDouble(buf, float64(v))
+		return nil
+	case float64:
+		writeJSONDouble(buf, v)
+		return nil
+	case time.Time:
+		buf.WriteString(`{"$date":`)
+		writeJSONString(buf, v.UTC().Format(time.RFC3339Nano))
+		buf.WriteByte('}')
+		return nil
+	case []byte:
+		buf.WriteString(`{"$binary":`)
+		writeJSONString(buf, base64.StdEncoding.EncodeToString(v))
+		buf.WriteByte('}')
+		return nil
+	case TypedMap:
+		return writeJSONMap(buf, v)
+	case TypedList:
+		return writeJSONList(buf, v)
+	}
+
+	if customType(reflect.TypeOf(v), DefaultClassRegistry) {
+		return writeJSONCustom(buf, v)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return writeJSON(buf, rv.Bool())
+	case reflect.String:
+		return writeJSON(buf, rv.String())
+	case reflect.Float32, reflect.Float64:
+		return writeJSON(buf, rv.Float())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return writeJSONInteger(buf, rv, rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 {
+			return fmt.Errorf("json: unsupported value %v: overflows long", v)
+		}
+		return writeJSONInteger(buf, rv, int64(rv.Uint()))
+	case reflect.Ptr:
+		if rv.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeJSON(buf, rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		// go int and uint as IntAuto of SerializerV1
+		l := TypedList{Type: listType(rv, IntAuto, DefaultClassRegistry), Items: make([]interface{}, rv.Len())}
+		k := rv.Type().Elem().Kind()
+		long := (k == reflect.Int || k == reflect.Uint) && intsAsLong(IntAuto, rv)
+		for i := range l.Items {
+			l.Items[i] = rv.Index(i).Interface()
+			if long && k == reflect.Int {
+				l.Items[i] = rv.Index(i).Int()
+			} else if long && rv.Index(i).Uint() <= math.MaxInt64 {
+				l.Items[i] = int64(rv.Index(i).Uint())
+			}
+		}
+		return writeJSONList(buf, l)
+	case reflect.Map:
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		var m TypedMap
+		for _, k := range keys {
+			m.Entries = append(m.Entries, MapEntry{Key: k.Interface(), Value: rv.MapIndex(k).Interface()})
+		}
+		return writeJSONMap(buf, m)
+	}
+	return fmt.Errorf("json: unsupported type %T", v)
+}
+
+// writeJSONCustom write v of a Marshaler or registered type as the hessian value SerializerV1 write
+func writeJSONCustom(buf *bytes.Buffer, v interface{}) error {
+	o := NewSerializerV1()
+	if err := o.WriteObject(v); err != nil {
+		return fmt.Errorf("json: %v", err)
+	}
+	i := NewDeserializerV1()
+	i.SetKeepTypes(true)
+	i.SetClassRegistry(nil)
+	hv, _, err := i.ReadValueAt(o.buf.Bytes(), 0)
+	if err != nil {
+		return fmt.Errorf("json: %v", err)
+	}
+	return writeJSON(buf, hv)
+}
+
+// writeJSONInteger write integer n of rv as SerializerV1, the sized kinds as their java types,
+// go int and uint as IntAuto
+func writeJSONInteger(buf *bytes.Buffer, rv reflect.Value, n int64) error {
+	switch rv.Kind() {
+	case reflect.Int64, reflect.Uint32, reflect.Uint64:
+		return writeJSON(buf, n)
+	case reflect.Int, reflect.Uint:
+		if intsAsLong(IntAuto, rv) {
+			return writeJSON(buf, n)
+		}
+	}
+	return writeJSON(buf, int32(n))
+}
+
+// writeJSONString write a JSON string without HTML escaping
+func writeJSONString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	// Encode append a newline
+	buf.Truncate(buf.Len() - 1)
+}
+
+// writeJSONDouble write {"$double": n}
+func writeJSONDouble(buf *bytes.Buffer, f float64) {
+	buf.WriteString(`{"$double":`)
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		writeJSONString(buf, strconv.FormatFloat(f, 'g', -1, 64))
+	} else {
+		buf.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	buf.WriteByte('}')
+}
+
+// writeJSONMap write typed map as object, or as $map pairs when a key is not a string
+func writeJSONMap(buf *bytes.Buffer, m TypedMap) error {
+	pairs := false
+	for _, e := range m.Entries {
+		if k, ok := e.Key.(string); !ok || strings.HasPrefix(k, "$") {
+			pairs = true
+			break
+		}
+	}
+
+	buf.WriteByte('{')
+	if len(m.Type) > 0 {
+		buf.WriteString(`"$class":`)
+		writeJSONString(buf, m.Type)
+		if pairs || len(m.Entries) > 0 {
+			buf.WriteByte(',')
+		}
+	}
+
+	if pairs {
+		buf.WriteString(`"$map":[`)
+		for i, e := range m.Entries {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('[')
+			if err := writeJSON(buf, e.Key); err != nil {
+				return err
+			}
+			buf.WriteByte(',')
+			if err := writeJSON(buf, e.Value); err != nil {
+				return err
+			}
+			buf.WriteByte(']')
+		}
+		buf.WriteString("]}")
+		return nil
+	}
+
+	for i, e := range m.Entries {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeJSONString(buf, e.Key.(string))
+		buf.WriteByte(':')
+		if err := writeJSON(buf, e.Value); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// writeJSONList write typed list as {"$list": [...], "$type": type}, or array when untyped
+func writeJSONList(buf *bytes.Buffer, l TypedList) error {
+	if len(l.Type) > 0 {
+		buf.WriteString(`{"$list":`)
+	}
+	buf.WriteByte('[')
+	for i, item := range l.Items {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := writeJSON(buf, item); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte(']')
+	if len(l.Type) > 0 {
+		buf.WriteString(`,"$type":`)
+		writeJSONString(buf, l.Type)
+		buf.WriteByte('}')
+	}
+	return nil
+}
+
+// FromJSON convert JSON written by ToJSON to hessian value, object members keep their order.
+//
+// Besides the ToJSON form, a plain number without fraction is a long when it is out of int
+// range, a double when it has fraction or exponent, and $long accept a string of number.
+func FromJSON(p []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+
+	v, err := readJSON(dec)
+	if err != nil {
+		return nil, fmt.Errorf("json: %v", err)
+	}
+	if _, err := dec.Token(); err == nil {
+		return nil, fmt.Errorf("json: unexpected data after value")
+	}
+
+	ans, err := fromJSON(v)
+	if err != nil {
+		return nil, fmt.Errorf("json: %v", err)
+	}
+	return ans, nil
+}
+
+// jsonObject JSON object with members in order
+type jsonObject []jsonMember
+
+type jsonMember struct {
+	Key   string
+	Value interface{}
+}
+
+func (o jsonObject) get(key string) (interface{}, bool) {
+	for _, m := range o {
+		if m.Key == key {
+			return m.Value, true
+		}
+	}
+	return nil, false
+}
+
+// readJSON read a JSON value, objects are read as jsonObject
+func readJSON(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		var obj jsonObject
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := readJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, jsonMember{Key: key.(string), Value: v})
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case json.Delim('['):
+		arr := []interface{}{}
+		for dec.More() {
+			v, err := readJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return arr, nil
+	}
+	return tok, nil
+}
+
+// fromJSON convert a value of readJSON to hessian value
+func fromJSON(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			if i >= math.MinInt32 && i <= math.MaxInt32 {
+				return int32(i), nil
+			}
+			return i, nil
+		}
+		return v.Float64()
+	case []interface{}:
+		items, err := fromJSONArray(v)
+		if err != nil {
+			return nil, err
+		}
+		return TypedList{Items: items}, nil
+	case jsonObject:
+		return fromJSONObject(v)
+	}
+	return v, nil
+}
+
+func fromJSONArray(arr []interface{}) ([]interface{}, error) {
+	ans := make([]interface{}, len(arr))
+	for i := range arr {
+		v, err := fromJSON(arr[i])
+		if err != nil {
+			return nil, err
+		}
+		ans[i] = v
+	}
+	return ans, nil
+}
+
+// fromJSONObject convert typed value, typed list or typed map
+func fromJSONObject(obj jsonObject) (interface{}, error) {
+	if len(obj) == 1 {
+		key, v := obj[0].Key, obj[0].Value
+		switch key {
+		case "$int":
+			i, err := jsonNumber(v).Int64()
+			if err != nil || i < math.MinInt32 || i > math.MaxInt32 {
+				return nil, fmt.Errorf("invalid $int %v", v)
+			}
+			return int32(i), nil
+		case "$long":
+			i, err := jsonNumber(v).Int64()
+			if err != nil {
+				return nil, fmt.Errorf("invalid $long %v", v)
+			}
+			return i, nil
+		case "$double":
+			f, err := strconv.ParseFloat(string(jsonNumber(v)), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid $double %v", v)
+			}
+			return f, nil
+		case "$date":
+			return jsonDate(v)
+		case "$binary":
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid $binary %v", v)
+			}
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid $binary: %v", err)
+			}
+			return b, nil
+		}
+	}
+
+	if items, ok := obj.get("$list"); ok {
+		var l TypedList
+		for _, m := range obj {
+			switch m.Key {
+			default:
+				return nil, fmt.Errorf("unknown key %q in $list", m.Key)
+			case "$list":
+			case "$type":
+				typ, ok := m.Value.(string)
+				if !ok {
+					return nil, fmt.Errorf("invalid $type %v", m.Value)
+				}
+				l.Type = typ
+			}
+		}
+		arr, ok := items.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid $list %v", items)
+		}
+		var err error
+		if l.Items, err = fromJSONArray(arr); err != nil {
+			return nil, err
+		}
+		return l, nil
+	}
+
+	var m TypedMap
+	if class, ok := obj.get("$class"); ok {
+		if m.Type, ok = class.(string); !ok {
+			return nil, fmt.Errorf("invalid $class %v", class)
+		}
+	}
+
+	if pairs, ok := obj.get("$map"); ok {
+		arr, ok := pairs.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid $map %v", pairs)
+		}
+		for _, pair := range arr {
+			kv, ok := pair.([]interface{})
+			if !ok || len(kv) != 2 {
+				return nil, fmt.Errorf("invalid $map entry %v", pair)
+			}
+			e, err := fromJSONArray(kv)
+			if err != nil {
+				return nil, err
+			}
+			m.Entries = append(m.Entries, MapEntry{Key: e[0], Value: e[1]})
+		}
+		for _, mb := range obj {
+			if mb.Key != "$class" && mb.Key != "$map" {
+				return nil, fmt.Errorf("unknown key %q in $map", mb.Key)
+			}
+		}
+		return m, nil
+	}
+
+	for _, mb := range obj {
+		if mb.Key == "$class" {
+			continue
+		}
+		if strings.HasPrefix(mb.Key, "$") {
+			return nil, fmt.Errorf("unknown key %q", mb.Key)
+		}
+		v, err := fromJSON(mb.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", mb.Key, err)
+		}
+		m.Entries = append(m.Entries, MapEntry{Key: mb.Key, Value: v})
+	}
+	return m, nil
+}
+
+// jsonNumber JSON number or string of number
+func jsonNumber(v interface{}) json.Number {
+	switch v := v.(type) {
+	case json.Number:
+		return v
+	case string:
+		return json.Number(v)
+	}
+	return ""
+}
+
+// jsonDate RFC 3339 string or milliseconds
+func jsonDate(v interface{}) (time.Time, error) {
+	switch v := v.(type) {
+	case json.Number:
+		ms, err := v.Int64()
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid $date %v", v)
+		}
+		return time.Unix(ms/1e3, ms%1e3*1e6), nil
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid $date: %v", err)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid $date %v", v)
+}
@@ -0,0 +1,283 @@
+package hessian
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type (
+	jsonCode int32
+	jsonID   int64
+	jsonFlag bool
+	jsonRate float64
+)
+
+func TestToJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    string
+		wantErr bool
+	}{
+		{name: "null", v: nil, want: `null`},
+		{name: "bool", v: true, want: `true`},
+		{name: "string", v: "<ggw>", want: `"<ggw>"`},
+		{name: "int", v: int32(-1), want: `-1`},
+		{name: "go int", v: 12, want: `12`},
+		{name: "go int overflow", v: 1 << 40, want: `{"$long":1099511627776}`},
+		{name: "long", v: int64(1), want: `{"$long":1}`},
+		{name: "int8", v: int8(-8), want: `-8`},
+		{name: "uint16", v: uint16(65535), want: `65535`},
+		{name: "uint32", v: uint32(1), want: `{"$long":1}`},
+		{name: "uint64", v: uint64(1 << 40), want: `{"$long":1099511627776}`},
+		{name: "uint64 overflow", v: uint64(math.MaxUint64), wantErr: true},
+		{name: "go uint", v: uint(12), want: `12`},
+		{name: "go uint overflow", v: uint(1 << 40), want: `{"$long":1099511627776}`},
+		{name: "named int32", v: jsonCode(7), want: `7`},
+		{name: "named int64", v: jsonID(7), want: `{"$long":7}`},
+		{name: "named string", v: enumRed, want: `"RED"`},
+		{name: "named bool", v: jsonFlag(true), want: `true`},
+		{name: "named float", v: jsonRate(0.5), want: `{"$double":0.5}`},
+		{name: "named list", v: []jsonCode{1, 2}, want: `{"$list":[1,2],"$type":"[int"}`},
+		{name: "Decimal", v: Decimal("1.5"), want: `{"$class":"java.math.BigDecimal","value":"1.5"}`},
+		{name: "UUID list", v: []UUID{{}}, want: `{"$list":[{"$class":"java.util.UUID","mostSigBits":{"$long":0},"leastSigBits":{"$long":0}}],"$type":"[object"}`},
+		{name: "nil BigInteger", v: (*big.Int)(nil), want: `null`},
+		{name: "Marshaler", v: money(150), want: `"1.50"`},
+		{name: "double", v: 1.5, want: `{"$double":1.5}`},
+		{name: "double NaN", v: math.NaN(), want: `{"$double":"NaN"}`},
+		{name: "date", v: time.Unix(1555604452, 0), want: `{"$date":"2019-04-18T16:20:52Z"}`},
+		{name: "binary", v: []byte("hello"), want: `{"$binary":"aGVsbG8="}`},
+		{
+			name: "typed map",
+			v: TypedMap{Type: "lab.ggw.User", Entries: []MapEntry{
+				{Key: "name", Value: "ggwhite"},
+				{Key: "age", Value: int32(18)},
+			}},
+			want: `{"$class":"lab.ggw.User","name":"ggwhite","age":18}`,
+		},
+		{name: "empty typed map", v: &TypedMap{Type: "lab.ggw.User"}, want: `{"$class":"lab.ggw.User"}`},
+		{
+			name: "map with int keys",
+			v: TypedMap{Entries: []MapEntry{
+				{Key: int32(1), Value: "one"},
+			}},
+			want: `{"$map":[[1,"one"]]}`,
+		},
+		{
+			name: "map with $ keys",
+			v: TypedMap{Type: "T", Entries: []MapEntry{
+				{Key: "$class", Value: "x"},
+			}},
+			want: `{"$class":"T","$map":[["$class","x"]]}`,
+		},
+		{
+			name: "typed list",
+			v:    TypedList{Type: "[int", Items: []interface{}{int32(1), int32(2)}},
+			want: `{"$list":[1,2],"$type":"[int"}`,
+		},
+		{name: "untyped list", v: TypedList{Items: []interface{}{}}, want: `[]`},
+		{name: "go slice", v: []string{"a"}, want: `{"$list":["a"],"$type":"[string"}`},
+		{
+			name: "go map",
+			v:    map[interface{}]interface{}{"b": int64(2), "a": nil},
+			want: `{"a":null,"b":{"$long":2}}`,
+		},
+		{name: "nil ptr", v: (*TypedMap)(nil), want: `null`},
+		{name: "unsupported", v: struct{}{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToJSON(tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ToJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("ToJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       string
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "int", p: `12`, want: int32(12)},
+		{name: "big int", p: `4294967296`, want: int64(4294967296)},
+		{name: "double", p: `1.5`, want: 1.5},
+		{name: "exponent", p: `1e2`, want: float64(100)},
+		{name: "string", p: `"ggwhite"`, want: "ggwhite"},
+		{name: "bool", p: `true`, want: true},
+		{name: "null", p: `null`, want: nil},
+		{name: "$int", p: `{"$int": 1}`, want: int32(1)},
+		{name: "$int overflow", p: `{"$int": 4294967296}`, wantErr: true},
+		{name: "$long", p: `{"$long": 1}`, want: int64(1)},
+		{name: "$long string", p: `{"$long": "9007199254740993"}`, want: int64(9007199254740993)},
+		{name: "$double", p: `{"$double": 2}`, want: float64(2)},
+		{name: "$double Inf", p: `{"$double": "-Inf"}`, want: math.Inf(-1)},
+		{name: "$date", p: `{"$date": "2019-04-18T16:20:52Z"}`, want: time.Date(2019, 4, 18, 16, 20, 52, 0, time.UTC)},
+		{name: "$date millis", p: `{"$date": 1555604452000}`, want: time.Unix(1555604452, 0)},
+		{name: "$date invalid", p: `{"$date": true}`, wantErr: true},
+		{name: "$binary", p: `{"$binary": "aGVsbG8="}`, want: []byte("hello")},
+		{name: "$binary invalid", p: `{"$binary": 1}`, wantErr: true},
+		{
+			name: "$class",
+			p:    `{"$class": "lab.ggw.User", "name": "ggwhite", "age": 18}`,
+			want: TypedMap{Type: "lab.ggw.User", Entries: []MapEntry{
+				{Key: "name", Value: "ggwhite"},
+				{Key: "age", Value: int32(18)},
+			}},
+		},
+		{
+			name: "map",
+			p:    `{"a": [1, {"$long": 2}]}`,
+			want: TypedMap{Entries: []MapEntry{
+				{Key: "a", Value: TypedList{Items: []interface{}{int32(1), int64(2)}}},
+			}},
+		},
+		{
+			name: "$map",
+			p:    `{"$map": [[1, "one"]], "$class": "T"}`,
+			want: TypedMap{Type: "T", Entries: []MapEntry{{Key: int32(1), Value: "one"}}},
+		},
+		{name: "$map invalid entry", p: `{"$map": [[1]]}`, wantErr: true},
+		{name: "$map unknown key", p: `{"$map": [], "a": 1}`, wantErr: true},
+		{
+			name: "$list",
+			p:    `{"$list": [1, 2], "$type": "[int"}`,
+			want: TypedList{Type: "[int", Items: []interface{}{int32(1), int32(2)}},
+		},
+		{name: "$list invalid", p: `{"$list": 1}`, wantErr: true},
+		{name: "$list unknown key", p: `{"$list": [], "$class": "T"}`, wantErr: true},
+		{name: "unknown key", p: `{"$foo": 1, "a": 1}`, wantErr: true},
+		{name: "invalid", p: `{`, wantErr: true},
+		{name: "trailing", p: `1 2`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromJSON([]byte(tt.p))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FromJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if tm, ok := got.(time.Time); ok {
+				if !tm.Equal(tt.want.(time.Time)) {
+					t.Errorf("FromJSON() = %v, want %v", got, tt.want)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromJSON() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSON_RoundTrip(t *testing.T) {
+	values := []interface{}{
+		nil,
+		true,
+		"ggwhite",
+		int32(math.MinInt32),
+		int64(math.MaxInt64),
+		math.Inf(1),
+		0.1,
+		time.Unix(1555604452, 123e6),
+		[]byte{0, 1, 2},
+		TypedMap{Type: "lab.ggw.User", Entries: []MapEntry{
+			{Key: "name", Value: "ggwhite"},
+			{Key: "id", Value: int64(1)},
+			{Key: "tags", Value: TypedList{Type: "[string", Items: []interface{}{"a", "b"}}},
+		}},
+		TypedMap{Entries: []MapEntry{
+			{Key: int32(2), Value: TypedMap{}},
+			{Key: int64(1), Value: TypedList{}},
+		}},
+		[]int64{1, 2},
+	}
+
+	o := NewSerializerV1()
+	for _, v := range values {
+		if err := o.WriteObject(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, _ := ioutil.ReadAll(o.Reader())
+
+	d := NewDeserializerV1()
+	d.SetKeepTypes(true)
+	decoded, _, err := d.ReadAt(want, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != len(values) {
+		t.Fatalf("ReadAt() = %d values, want %d", len(decoded), len(values))
+	}
+
+	o = NewSerializerV1()
+	for _, v := range decoded {
+		p, err := ToJSON(v)
+		if err != nil {
+			t.Fatalf("ToJSON(%#v) error = %v", v, err)
+		}
+		back, err := FromJSON(p)
+		if err != nil {
+			t.Fatalf("FromJSON(%s) error = %v", p, err)
+		}
+		if err := o.WriteObject(back); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, _ := ioutil.ReadAll(o.Reader())
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("round trip = %v, want %v", got, want)
+	}
+}
+
+func TestPlainValue(t *testing.T) {
+	type User struct {
+		Package `hessian:"lab.ggw.User"`
+		Name    string `hessian:"name"`
+		Age     int
+		secret  string
+	}
+	uuid, _ := ParseUUID("123e4567-e89b-12d3-a456-426614174000")
+	v := []interface{}{
+		map[interface{}]interface{}{int32(1): &User{Name: "ggwhite", Age: 18, secret: "x"}},
+		TypedList{Items: []interface{}{TypedMap{Entries: []MapEntry{{Key: "a", Value: nil}}}}},
+		(*User)(nil),
+		map[interface{}]interface{}{nil: true, uuid: uuid},
+		TypedMap{Entries: []MapEntry{{Key: []byte("k"), Value: math.Inf(1)}}},
+		(*UUID)(nil),
+	}
+	want := []interface{}{
+		map[string]interface{}{"1": map[string]interface{}{"name": "ggwhite", "Age": 18}},
+		[]interface{}{map[string]interface{}{"a": nil}},
+		nil,
+		map[string]interface{}{"null": true, uuid.String(): uuid},
+		map[string]interface{}{"aw==": "+Inf"},
+		nil,
+	}
+	if got := PlainValue(v); !reflect.DeepEqual(got, want) {
+		t.Errorf("PlainValue() = %#v, want %#v", got, want)
+	}
+
+	p, err := json.Marshal(PlainValue(uuid))
+	if err != nil || string(p) != `"123e4567-e89b-12d3-a456-426614174000"` {
+		t.Errorf("json.Marshal(PlainValue()) = %s, %v", p, err)
+	}
+}
@@ -0,0 +1,109 @@
+package hessian
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type mangle int
+
+// Method name mangling modes, for overloaded java methods
+const (
+	// MangleNone send the method name as is
+	MangleNone mangle = iota
+	// MangleArity append the number of arguments, ex: add__2
+	MangleArity
+	// MangleType append the java type of each argument, ex: add_int_int
+	MangleType
+)
+
+// MangleName derive the mangled method name from method name and arguments, go int and uint are
+// mangled as written by IntStrict
+func MangleName(mode mangle, m string, args ...interface{}) (string, error) {
+	return mangleName(mode, IntStrict, m, args...)
+}
+
+// mangleName derive the mangled method name, go int and uint are mangled as written by policy p
+func mangleName(mode mangle, p intPolicy, m string, args ...interface{}) (string, error) {
+	switch mode {
+	default:
+		return "", fmt.Errorf("unknown mangle mode %d", mode)
+	case MangleNone:
+		return m, nil
+	case MangleArity:
+		return m + "__" + strconv.Itoa(len(args)), nil
+	case MangleType:
+		var sb strings.Builder
+		sb.WriteString(m)
+		for i, arg := range args {
+			if arg == nil {
+				return "", fmt.Errorf("can not mangle nil argument %d of method %s", i, m)
+			}
+			name, err := mangleType(reflect.TypeOf(arg))
+			if err != nil {
+				return "", err
+			}
+			if t := reflect.TypeOf(arg); (t.Kind() == reflect.Int || t.Kind() == reflect.Uint) && !t.Implements(marshalerType) {
+				name = mangleInteger(reflect.ValueOf(arg), p)
+			} else if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) &&
+				(t.Elem().Kind() == reflect.Int || t.Elem().Kind() == reflect.Uint) && !t.Elem().Implements(marshalerType) {
+				name = "[" + mangleInteger(reflect.ValueOf(arg), p)
+			}
+			sb.WriteByte('_')
+			sb.WriteString(name)
+		}
+		return sb.String(), nil
+	}
+}
+
+// mangleType return java type name of given go type the same way as hessian server.
+//
+// Class types use the simple name, ex: lab.ggw.demo.User -> User
+func mangleType(t reflect.Type) (string, error) {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16:
+		return "int", nil
+	case reflect.Int64, reflect.Uint32, reflect.Uint64:
+		return "long", nil
+	case reflect.Float32, reflect.Float64:
+		return "double", nil
+	case reflect.String:
+		return "string", nil
+	case reflect.Map:
+		return "Map", nil
+	case reflect.Interface:
+		return "Object", nil
+	case reflect.Array, reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "binary", nil
+		}
+		name, err := mangleType(t.Elem())
+		if err != nil {
+			return "", err
+		}
+		return "[" + name, nil
+	case reflect.Struct, reflect.Ptr:
+		if t == reflect.TypeOf(time.Time{}) {
+			return "date", nil
+		}
+		pkg := PackageOf(t)
+		if len(pkg) == 0 {
+			return "", fmt.Errorf("can not mangle type %s without Package field", t)
+		}
+		return pkg[strings.LastIndex(pkg, ".")+1:], nil
+	}
+	return "", fmt.Errorf("can not mangle type %s", t)
+}
+
+// mangleInteger java type name of go int or uint v, or of the items of list v, written by policy p
+func mangleInteger(v reflect.Value, p intPolicy) string {
+	if intsAsLong(p, v) {
+		return "long"
+	}
+	return "int"
+}
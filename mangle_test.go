@@ -0,0 +1,162 @@
+package hessian
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMangleName(t *testing.T) {
+	type User struct {
+		Package `hessian:"lab.ggw.shs.service.User"`
+		Name    string `hessian:"name"`
+	}
+	type Account struct {
+		Name string
+	}
+	type args struct {
+		mode mangle
+		m    string
+		args []interface{}
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "None",
+			args: args{
+				mode: MangleNone,
+				m:    "add",
+				args: []interface{}{int32(1), int32(2)},
+			},
+			want:    "add",
+			wantErr: false,
+		},
+		{
+			name: "Arity",
+			args: args{
+				mode: MangleArity,
+				m:    "add",
+				args: []interface{}{int32(1), int32(2)},
+			},
+			want:    "add__2",
+			wantErr: false,
+		},
+		{
+			name: "Arity without arguments",
+			args: args{
+				mode: MangleArity,
+				m:    "add",
+			},
+			want:    "add__0",
+			wantErr: false,
+		},
+		{
+			name: "Type int & long",
+			args: args{
+				mode: MangleType,
+				m:    "add",
+				args: []interface{}{int32(1), int64(2)},
+			},
+			want:    "add_int_long",
+			wantErr: false,
+		},
+		{
+			name: "Type primitives",
+			args: args{
+				mode: MangleType,
+				m:    "call",
+				args: []interface{}{"s", true, 1.5, time.Now(), []byte("b"), []string{"a"}, map[string]int{}},
+			},
+			want:    "call_string_boolean_double_date_binary_[string_Map",
+			wantErr: false,
+		},
+		{
+			name: "Type struct",
+			args: args{
+				mode: MangleType,
+				m:    "objI",
+				args: []interface{}{User{}, &User{}, []*User{}},
+			},
+			want:    "objI_User_User_[User",
+			wantErr: false,
+		},
+		{
+			name: "Type struct without Package",
+			args: args{
+				mode: MangleType,
+				m:    "objI",
+				args: []interface{}{Account{}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Type nil",
+			args: args{
+				mode: MangleType,
+				m:    "objI",
+				args: []interface{}{nil},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Type chan",
+			args: args{
+				mode: MangleType,
+				m:    "objI",
+				args: []interface{}{make(chan int)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unknown mode",
+			args: args{
+				mode: mangle(123),
+				m:    "add",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MangleName(tt.args.mode, tt.args.m, tt.args.args...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MangleName() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("MangleName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMangleName_IntPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy intPolicy
+		args   []interface{}
+		want   string
+	}{
+		{name: "strict", policy: IntStrict, args: []interface{}{1, uint(1)}, want: "add_int_int"},
+		{name: "auto", policy: IntAuto, args: []interface{}{1, 1 << 40, uint(1 << 40)}, want: "add_int_long_long"},
+		{name: "always long", policy: IntAlwaysLong, args: []interface{}{1, int32(1)}, want: "add_long_int"},
+		{name: "sized unsigned", policy: IntStrict, args: []interface{}{uint16(1), uint32(1)}, want: "add_int_long"},
+		{name: "strict lists", policy: IntStrict, args: []interface{}{[]int{1}, []uint{1}}, want: "add_[int_[int"},
+		{name: "auto lists", policy: IntAuto, args: []interface{}{[]int{1}, []int{1, 1 << 40}, [1]uint{1 << 40}}, want: "add_[int_[long_[long"},
+		{name: "always long lists", policy: IntAlwaysLong, args: []interface{}{[]int{1}, []uint{1}}, want: "add_[long_[long"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mangleName(MangleType, tt.policy, "add", tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("mangleName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package hessian
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// CodecConfig config for NewCodec
+type CodecConfig struct {
+	Version version
+	// TypeMap optional, class name to go type, objects of a registered class are decoded to the type
+	// when the target is an interface
+	TypeMap map[string]reflect.Type
+	// Classes optional, class codecs, default DefaultClassRegistry
+	Classes *ClassRegistry
+	// IntPolicy optional, how go int and uint are written, default IntStrict
+	IntPolicy intPolicy
+}
+
+// Validate Codec Config
+func (c *CodecConfig) Validate() error {
+	if c.TypeMap == nil {
+		c.TypeMap = make(map[string]reflect.Type)
+	}
+
+	if c.Classes == nil {
+		c.Classes = DefaultClassRegistry
+	}
+
+	if c.IntPolicy < IntStrict || c.IntPolicy > IntAlwaysLong {
+		return fmt.Errorf("Codec Config: unknown IntPolicy %d", c.IntPolicy)
+	}
+
+	switch c.Version {
+	default:
+		return fmt.Errorf("Codec Config: unknown Version %d", c.Version)
+	case V1:
+	case V2:
+		return fmt.Errorf("Codec Config: Hessian V2.0 is unsupported")
+	}
+	return nil
+}
+
+// Codec encode and decode single hessian values without a call or reply envelope, ex: for caches,
+// message queues or files. It is safe for concurrent use once its types are registered.
+type Codec struct {
+	conf *CodecConfig
+}
+
+// NewCodec create a codec of the config
+func NewCodec(c *CodecConfig) (*Codec, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &Codec{conf: c}, nil
+}
+
+// RegisterType input a type with Package field, register type mapping
+func (c *Codec) RegisterType(t reflect.Type) error {
+	pkg := PackageOf(t)
+
+	if len(pkg) == 0 {
+		return fmt.Errorf("input type is without Package field")
+	}
+
+	c.conf.TypeMap[pkg] = t
+	return nil
+}
+
+// Marshal return the hessian encoding of v
+func (c *Codec) Marshal(v interface{}) ([]byte, error) {
+	o := NewSerializerV1()
+	o.SetTypeMap(c.conf.TypeMap)
+	o.SetClassRegistry(c.conf.Classes)
+	o.SetIntPolicy(c.conf.IntPolicy)
+	if err := o.WriteObject(v); err != nil {
+		return nil, err
+	}
+	return o.buf.Bytes(), nil
+}
+
+// Unmarshal decode hessian encoded p into v, which must be a non-nil pointer.
+//
+// The value is converted to the type v points to, like Proxy.InvokeInto. Trailing data after
+// the value is an error.
+func (c *Codec) Unmarshal(p []byte, v interface{}) (err error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("Unmarshal input is not a non-nil pointer")
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("unmarshal: malformed input")
+		}
+	}()
+
+	i := NewDeserializerV1()
+	i.SetTypeMap(c.conf.TypeMap)
+	i.SetClassRegistry(c.conf.Classes)
+
+	e, idx, err := i.readInto(p, 0, rv.Elem().Type())
+	if err != nil {
+		return fmt.Errorf("unmarshal: %v", err)
+	}
+	if idx != len(p)-1 {
+		return fmt.Errorf("unmarshal: unexpected data at offset %d", idx+1)
+	}
+	rv.Elem().Set(e)
+	return nil
+}
+
+// defaultCodec hessian 1.0 codec without type map, with DefaultClassRegistry
+var defaultCodec = &Codec{conf: &CodecConfig{Version: V1, TypeMap: make(map[string]reflect.Type), Classes: DefaultClassRegistry}}
+
+// Marshal return the hessian 1.0 encoding of v
+func Marshal(v interface{}) ([]byte, error) {
+	return defaultCodec.Marshal(v)
+}
+
+// Unmarshal decode hessian 1.0 encoded p into v, which must be a non-nil pointer, see Codec.Unmarshal
+func Unmarshal(p []byte, v interface{}) error {
+	return defaultCodec.Unmarshal(p, v)
+}
@@ -0,0 +1,175 @@
+package hessian
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type marshalUser struct {
+	Package `hessian:"lab.ggw.User"`
+	Name    string `hessian:"name"`
+	Age     int32  `hessian:"age"`
+}
+
+func TestCodecConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    *CodecConfig
+		wantErr bool
+	}{
+		{name: "V1", conf: &CodecConfig{Version: V1}, wantErr: false},
+		{name: "V2", conf: &CodecConfig{Version: V2}, wantErr: true},
+		{name: "unknown", conf: &CodecConfig{Version: 9}, wantErr: true},
+		{name: "unknown IntPolicy", conf: &CodecConfig{Version: V1, IntPolicy: intPolicy(9)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.conf.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("CodecConfig.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    []byte
+		wantErr bool
+	}{
+		{name: "nil", v: nil, want: []byte{'N'}},
+		{name: "int", v: 1, want: []byte{'I', 0, 0, 0, 1}},
+		{name: "string", v: "abc", want: []byte{'S', 0, 3, 'a', 'b', 'c'}},
+		{
+			name: "struct",
+			v:    &marshalUser{Name: "a", Age: 1},
+			want: []byte{
+				'M', 't', 0, 12, 'l', 'a', 'b', '.', 'g', 'g', 'w', '.', 'U', 's', 'e', 'r',
+				'S', 0, 4, 'n', 'a', 'm', 'e', 'S', 0, 1, 'a',
+				'S', 0, 3, 'a', 'g', 'e', 'I', 0, 0, 0, 1,
+				'z',
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Marshal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Marshal() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	date := time.Date(2019, 4, 18, 16, 20, 52, 0, time.Local)
+
+	var i int
+	var i8 int8
+	var s string
+	var d time.Time
+	var u marshalUser
+	var pu *marshalUser
+	var names []string
+	var any interface{}
+
+	tests := []struct {
+		name    string
+		v       interface{}
+		in      interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "int", v: &i, in: int32(7), want: 7},
+		{name: "overflow", v: &i8, in: int32(300), wantErr: true},
+		{name: "string", v: &s, in: "abc", want: "abc"},
+		{name: "date", v: &d, in: date, want: date},
+		{name: "struct", v: &u, in: &marshalUser{Name: "a", Age: 1}, want: marshalUser{Name: "a", Age: 1}},
+		{name: "struct ptr", v: &pu, in: &marshalUser{Name: "a"}, want: &marshalUser{Name: "a"}},
+		{name: "null", v: &pu, in: nil, want: (*marshalUser)(nil)},
+		{name: "slice", v: &names, in: []string{"a", "b"}, want: []string{"a", "b"}},
+		{name: "interface", v: &any, in: int64(1), want: int64(1)},
+		{name: "mismatch", v: &s, in: int32(1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Marshal(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Unmarshal(p, tt.v); (err != nil) != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := reflect.ValueOf(tt.v).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unmarshal() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+	var s string
+	tests := []struct {
+		name string
+		p    []byte
+		v    interface{}
+	}{
+		{name: "not pointer", p: []byte{'N'}, v: s},
+		{name: "nil pointer", p: []byte{'N'}, v: (*string)(nil)},
+		{name: "empty", p: nil, v: &s},
+		{name: "truncated", p: []byte{'S', 0, 3, 'a'}, v: &s},
+		{name: "trailing data", p: []byte{'N', 'N'}, v: &s},
+		{name: "unknown tag", p: []byte{'?'}, v: &s},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Unmarshal(tt.p, tt.v); err == nil {
+				t.Errorf("Unmarshal() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestCodec_RegisterType(t *testing.T) {
+	c, err := NewCodec(&CodecConfig{Version: V1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RegisterType(reflect.TypeOf(0)); err == nil {
+		t.Errorf("Codec.RegisterType() error = nil, want error")
+	}
+	if err := c.RegisterType(reflect.TypeOf(&marshalUser{})); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := c.Marshal(marshalUser{Name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v interface{}
+	if err := c.Unmarshal(p, &v); err != nil {
+		t.Fatal(err)
+	}
+	if want := (&marshalUser{Name: "a"}); !reflect.DeepEqual(v, want) {
+		t.Errorf("Codec.Unmarshal() = %#v, want %#v", v, want)
+	}
+
+	// without type map the object is a map
+	if err := Unmarshal(p, &v); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[interface{}]interface{}{"name": "a", "age": int32(0)}; !reflect.DeepEqual(v, want) {
+		t.Errorf("Unmarshal() = %#v, want %#v", v, want)
+	}
+}
@@ -0,0 +1,34 @@
+package hessian
+
+import (
+	"bytes"
+	"reflect"
+)
+
+// Marshaler a type which write its own hessian representation, MarshalHessian must write exactly
+// one value to enc. It is checked before any other rule of SerializerV1.WriteObject.
+type Marshaler interface {
+	MarshalHessian(enc *Encoder) error
+}
+
+// Unmarshaler a type which read its own hessian representation, UnmarshalHessian read one value
+// from dec by Decode or Token. It is implemented by pointer receiver and checked before any other
+// rule when a value is decoded into the type.
+type Unmarshaler interface {
+	UnmarshalHessian(dec *Decoder) error
+}
+
+var (
+	marshalerType   = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+)
+
+// unmarshalHessian decode one value encoded in p into a new value of type t by its Unmarshaler,
+// the decoder is of codec
+func unmarshalHessian(p []byte, t reflect.Type, codec *Codec) (reflect.Value, error) {
+	v := reflect.New(t)
+	if err := v.Interface().(Unmarshaler).UnmarshalHessian(codec.NewDecoder(bytes.NewReader(p))); err != nil {
+		return reflect.Value{}, err
+	}
+	return v.Elem(), nil
+}
@@ -0,0 +1,159 @@
+package hessian
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// money cents written as a decimal string
+type money int64
+
+func (m money) MarshalHessian(enc *Encoder) error {
+	return enc.Encode(fmt.Sprintf("%d.%02d", m/100, m%100))
+}
+
+func (m *money) UnmarshalHessian(dec *Decoder) error {
+	var s string
+	if err := dec.Decode(&s); err != nil {
+		return err
+	}
+	var units, cents int64
+	if _, err := fmt.Sscanf(s, "%d.%d", &units, &cents); err != nil {
+		return fmt.Errorf("invalid money %q", s)
+	}
+	*m = money(units*100 + cents)
+	return nil
+}
+
+// orderID read from int or long token
+type orderID struct {
+	n int64
+}
+
+func (id *orderID) MarshalHessian(enc *Encoder) error {
+	return enc.Encode(id.n)
+}
+
+func (id *orderID) UnmarshalHessian(dec *Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch n := tok.(type) {
+	case int32:
+		id.n = int64(n)
+	case int64:
+		id.n = n
+	default:
+		return fmt.Errorf("invalid order id %v", tok)
+	}
+	return nil
+}
+
+type order struct {
+	Package `hessian:"lab.ggw.Order"`
+	ID      *orderID `hessian:"id"`
+	Price   money    `hessian:"price"`
+}
+
+func TestMarshaler(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want []byte
+	}{
+		{name: "value", v: money(1234), want: []byte{'S', 0, 5, '1', '2', '.', '3', '4'}},
+		{name: "pointer", v: &orderID{n: 1}, want: []byte{'L', 0, 0, 0, 0, 0, 0, 0, 1}},
+		{name: "nil pointer", v: (*orderID)(nil), want: []byte{'N'}},
+		{name: "list", v: []money{1}, want: []byte{'V', 't', 0, 7, '[', 'o', 'b', 'j', 'e', 'c', 't', 'l', 0, 0, 0, 1, 'S', 0, 4, '0', '.', '0', '1', 'z'}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Marshal() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshaler(t *testing.T) {
+	p, err := Marshal(&order{ID: &orderID{n: 7}, Price: 1999})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := order{ID: &orderID{n: 7}, Price: 1999}
+
+	var got order
+	if err := Unmarshal(p, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %+v, want %+v", got, want)
+	}
+
+	var list []order
+	var buf bytes.Buffer
+	NewEncoder(&buf).Encode([]*order{&want})
+	if err := NewDecoder(&buf).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(list, []order{want}) {
+		t.Errorf("Decoder.Decode() = %+v, want %+v", list, []order{want})
+	}
+
+	// from a decoded value
+	v, err := convertValue(map[interface{}]interface{}{"id": int32(7), "price": "19.99"}, reflect.TypeOf(order{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v.Interface(), want) {
+		t.Errorf("convertValue() = %+v, want %+v", v.Interface(), want)
+	}
+
+	var m money
+	if err := Unmarshal([]byte{'S', 0, 1, 'x'}, &m); err == nil {
+		t.Errorf("Unmarshal() error = nil, want error")
+	}
+}
+
+type orderService struct{}
+
+func (orderService) Total(orders []order) money {
+	var ans money
+	for _, o := range orders {
+		ans += o.Price
+	}
+	return ans
+}
+
+func TestMarshaler_Server(t *testing.T) {
+	s, err := NewServer(orderService{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	proxy, err := NewProxy(&ProxyConfig{Version: V1, URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var total money
+	err = proxy.InvokeInto(context.Background(), &total, "total", []order{{Price: 100}, {Price: 250}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 350 {
+		t.Errorf("Proxy.InvokeInto() = %v, want 350", total)
+	}
+}
@@ -1,4 +1,23 @@
 package hessian
 
+import "reflect"
+
 // Package pojo class name, ex: lab.ggw.demo.User
 type Package string
+
+// PackageOf find the Package tag of a struct or ptr(of struct) type, return empty string if not found
+func PackageOf(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return ""
+	}
+
+	for i, l := 0, t.NumField(); i < l; i++ {
+		if t.Field(i).Type == reflect.TypeOf(Package("")) {
+			return t.Field(i).Tag.Get(tagName)
+		}
+	}
+	return ""
+}
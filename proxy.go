@@ -1,7 +1,10 @@
 package hessian
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"reflect"
@@ -21,6 +24,22 @@ type ProxyConfig struct {
 	URL     string
 	TypeMap map[string]reflect.Type
 	Client  *http.Client
+
+	// Credentials optional, apply authentication to every request
+	Credentials Credentials
+	// Header optional, static headers set to every request
+	Header http.Header
+
+	// Compression optional, compress request body by gzip or deflate
+	Compression compression
+	// CompressionThreshold compress request body only when its size is bigger than threshold
+	CompressionThreshold int
+
+	// Mangle optional, mangle method name by arguments for overloaded methods
+	Mangle mangle
+
+	// IntPolicy optional, how go int and uint arguments are written, default IntStrict
+	IntPolicy intPolicy
 }
 
 // Validate Proxy Config
@@ -37,71 +56,210 @@ func (c *ProxyConfig) Validate() error {
 		c.Client = &http.Client{}
 	}
 
+	if c.Compression < CompressNone || c.Compression > CompressDeflate {
+		return fmt.Errorf("Proxy Config: unknown Compression %d", c.Compression)
+	}
+
+	if c.CompressionThreshold < 0 {
+		return fmt.Errorf("Proxy Config: CompressionThreshold must not be negative")
+	}
+
+	if c.Mangle < MangleNone || c.Mangle > MangleType {
+		return fmt.Errorf("Proxy Config: unknown Mangle %d", c.Mangle)
+	}
+
+	if c.IntPolicy < IntStrict || c.IntPolicy > IntAlwaysLong {
+		return fmt.Errorf("Proxy Config: unknown IntPolicy %d", c.IntPolicy)
+	}
+
 	return nil
 }
 
-// Proxy hessian proxy
+// Proxy hessian proxy, it is safe for concurrent use once its types are registered
 type Proxy struct {
-	conf         *ProxyConfig
-	client       *http.Client
-	serializer   Serializer
-	deserializer Deserializer
+	conf   *ProxyConfig
+	client *http.Client
 }
 
 // Invoke input method name and arguments, it will send request to server, and parse response to interface
 func (c *Proxy) Invoke(m string, args ...interface{}) ([]interface{}, error) {
+	return c.InvokeContext(context.Background(), m, args...)
+}
 
-	c.serializer.Flush()
+// InvokeContext same as Invoke, the request is canceled when ctx is done
+func (c *Proxy) InvokeContext(ctx context.Context, m string, args ...interface{}) ([]interface{}, error) {
+	p, err := c.invoke(ctx, m, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// a deserializer per call, the proxy is safe for concurrent use
+	i := NewDeserializerV1()
+	i.SetTypeMap(c.conf.TypeMap)
+	i.Reset(bytes.NewReader(p))
 
-	if err := c.serializer.Call(m, args...); err != nil {
+	ans, err := i.Read()
+	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, c.conf.URL, c.serializer.Reader())
+	return ans, nil
+}
+
+// invoke send the call to server, return the decompressed reply
+func (c *Proxy) invoke(ctx context.Context, m string, args ...interface{}) ([]byte, error) {
+	m, err := mangleName(c.conf.Mangle, c.conf.IntPolicy, m, args...)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "x-application/hessian")
-	req.Header.Set("Accept-Encoding", "deflate")
+	// a serializer per call, the proxy is safe for concurrent use
+	o := NewSerializerV1()
+	o.SetTypeMap(c.conf.TypeMap)
+	o.SetIntPolicy(c.conf.IntPolicy)
+	if err := o.Call(m, args...); err != nil {
+		return nil, err
+	}
+	body := o.buf.Bytes()
 
-	resp, err := c.client.Do(req)
+	var encoding string
+	if c.conf.Compression != CompressNone && len(body) > c.conf.CompressionThreshold {
+		if body, err = compress(c.conf.Compression, body); err != nil {
+			return nil, err
+		}
+		encoding = c.conf.Compression.contentEncoding()
+	}
+
+	resp, req, err := c.do(ctx, body, encoding)
 	if err != nil {
 		return nil, err
 	}
+
+	// refresh credentials and retry once
+	if r, ok := refresherOf(c.conf.Credentials); ok && resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		if err := refresh(r, req); err != nil {
+			return nil, err
+		}
+		if resp, _, err = c.do(ctx, body, encoding); err != nil {
+			return nil, err
+		}
+	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("hessian: http status %s", resp.Status)
+	}
 	if resp.StatusCode != http.StatusOK {
 		log.Println("status error", resp.StatusCode)
 	}
 
-	c.deserializer.Reset(resp.Body)
-
-	ans, err := c.deserializer.Read()
+	r, err := decompress(resp.Header.Get("Content-Encoding"), resp.Body)
 	if err != nil {
 		return nil, err
 	}
+	defer r.Close()
 
-	return ans, nil
+	return ioutil.ReadAll(r)
 }
 
-// RegisterType input a type with Package field, register type mapping
-func (c *Proxy) RegisterType(t reflect.Type) error {
-	var tmp reflect.Type
-	var pkg string
+// InvokeInto same as InvokeContext, and convert the first reply value into v, which must be a pointer.
+// If v is nil, the reply is ignored. RawMessage in v get the exact bytes of its value, Unmarshaler in
+// v decode its value by itself.
+func (c *Proxy) InvokeInto(ctx context.Context, v interface{}, m string, args ...interface{}) error {
+	if v != nil {
+		if rv := reflect.ValueOf(v); rv.Kind() != reflect.Ptr || rv.IsNil() {
+			return fmt.Errorf("InvokeInto input is not a non-nil pointer")
+		}
+		if readsBytes(reflect.TypeOf(v), make(map[reflect.Type]bool)) {
+			return c.invokeRaw(ctx, v, m, args...)
+		}
+	}
+
+	ans, err := c.InvokeContext(ctx, m, args...)
+	if err != nil {
+		return err
+	}
+
+	if v == nil || len(ans) == 0 {
+		return nil
+	}
+
+	rv := reflect.ValueOf(v).Elem()
+	val, err := convertValue(ans[0], rv.Type())
+	if err != nil {
+		return err
+	}
+	rv.Set(val)
+	return nil
+}
+
+// invokeRaw same as InvokeInto, read the reply value into v by its bytes
+func (c *Proxy) invokeRaw(ctx context.Context, v interface{}, m string, args ...interface{}) (err error) {
+	p, err := c.invoke(ctx, m, args...)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("parse reply: malformed input")
+		}
+	}()
+
+	i := NewDeserializerV1()
+	i.SetTypeMap(c.conf.TypeMap)
+
+	// check reply and fault first
+	if _, _, _, err := i.ReadReplyAt(p, 0); err != nil {
+		return err
+	}
+	_, idx, err := i.readReplyHeaders(p, 0)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v).Elem()
+	val, _, err := i.readInto(p, idx, rv.Type())
+	if err != nil {
+		return err
+	}
+	rv.Set(val)
+	return nil
+}
 
-	if t.Kind() == reflect.Ptr {
-		tmp = t.Elem()
-	} else {
-		tmp = t
+// do send request body to server with headers and credentials, encoding is Content-Encoding of body.
+// It return the response and the request signed by credentials.
+func (c *Proxy) do(ctx context.Context, body []byte, encoding string) (*http.Response, *http.Request, error) {
+	req, err := http.NewRequest(http.MethodPost, c.conf.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
 	}
 
-	for i, l := 0, tmp.NumField(); i < l; i++ {
-		if tmp.Field(i).Type == reflect.TypeOf(Package("")) {
-			pkg = tmp.Field(i).Tag.Get(tagName)
-			break
+	for k, vs := range c.conf.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
 		}
 	}
+	req.Header.Set("Content-Type", "x-application/hessian")
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	if len(encoding) > 0 {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+
+	if c.conf.Credentials != nil {
+		if err := c.conf.Credentials.Apply(req); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	resp, err := c.client.Do(req.WithContext(ctx))
+	return resp, req, err
+}
+
+// RegisterType input a type with Package field, register type mapping
+func (c *Proxy) RegisterType(t reflect.Type) error {
+	pkg := PackageOf(t)
 
 	if len(pkg) == 0 {
 		return fmt.Errorf("input type is without Package field")
@@ -109,9 +267,6 @@ func (c *Proxy) RegisterType(t reflect.Type) error {
 
 	c.conf.TypeMap[pkg] = t
 
-	c.serializer.SetTypeMap(c.conf.TypeMap)
-	c.deserializer.SetTypeMap(c.conf.TypeMap)
-
 	return nil
 }
 
@@ -126,10 +281,8 @@ func NewProxy(c *ProxyConfig) (*Proxy, error) {
 		return nil, fmt.Errorf("Please set proxy version is V1 or V2")
 	case V1:
 		return &Proxy{
-			conf:         c,
-			client:       c.Client,
-			serializer:   NewSerializerV1(),
-			deserializer: NewDeserializerV1(),
+			conf:   c,
+			client: c.Client,
 		}, nil
 	case V2:
 		return nil, fmt.Errorf("Hessian V2.0 is unsupported")
@@ -1,17 +1,22 @@
 package hessian
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"sync"
 	"testing"
 )
 
 func TestProxyConfig_Validate(t *testing.T) {
 	type fields struct {
-		Version version
-		URL     string
-		TypeMap map[string]reflect.Type
-		Client  *http.Client
+		Version     version
+		URL         string
+		TypeMap     map[string]reflect.Type
+		Client      *http.Client
+		Compression compression
 	}
 	tests := []struct {
 		name    string
@@ -83,14 +88,33 @@ func TestProxyConfig_Validate(t *testing.T) {
 				Client:  &http.Client{},
 			},
 		},
+		{
+			name: "Unknown Compression",
+			fields: fields{
+				Version:     V1,
+				URL:         "http://localhost:8080/simple",
+				TypeMap:     make(map[string]reflect.Type),
+				Client:      &http.Client{},
+				Compression: compression(123),
+			},
+			wantErr: true,
+			want: &ProxyConfig{
+				Version:     V1,
+				URL:         "http://localhost:8080/simple",
+				TypeMap:     make(map[string]reflect.Type),
+				Client:      &http.Client{},
+				Compression: compression(123),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &ProxyConfig{
-				Version: tt.fields.Version,
-				URL:     tt.fields.URL,
-				TypeMap: tt.fields.TypeMap,
-				Client:  tt.fields.Client,
+				Version:     tt.fields.Version,
+				URL:         tt.fields.URL,
+				TypeMap:     tt.fields.TypeMap,
+				Client:      tt.fields.Client,
+				Compression: tt.fields.Compression,
 			}
 			if err := c.Validate(); (err != nil) != tt.wantErr {
 				t.Errorf("ProxyConfig.Validate() error = %v, wantErr %v", err, tt.wantErr)
@@ -115,10 +139,8 @@ func TestProxy_RegisterType(t *testing.T) {
 		Name string
 	}
 	type fields struct {
-		conf         *ProxyConfig
-		client       *http.Client
-		serializer   Serializer
-		deserializer Deserializer
+		conf   *ProxyConfig
+		client *http.Client
 	}
 	type args struct {
 		t reflect.Type
@@ -139,9 +161,7 @@ func TestProxy_RegisterType(t *testing.T) {
 					TypeMap: make(map[string]reflect.Type),
 					Client:  &http.Client{},
 				},
-				client:       &http.Client{},
-				serializer:   NewSerializerV1(),
-				deserializer: NewDeserializerV1(),
+				client: &http.Client{},
 			},
 			args: args{
 				t: reflect.TypeOf(&User{}),
@@ -160,9 +180,7 @@ func TestProxy_RegisterType(t *testing.T) {
 					TypeMap: make(map[string]reflect.Type),
 					Client:  &http.Client{},
 				},
-				client:       &http.Client{},
-				serializer:   NewSerializerV1(),
-				deserializer: NewDeserializerV1(),
+				client: &http.Client{},
 			},
 			args: args{
 				t: reflect.TypeOf(User{}),
@@ -183,9 +201,7 @@ func TestProxy_RegisterType(t *testing.T) {
 					},
 					Client: &http.Client{},
 				},
-				client:       &http.Client{},
-				serializer:   NewSerializerV1(),
-				deserializer: NewDeserializerV1(),
+				client: &http.Client{},
 			},
 			args: args{
 				t: reflect.TypeOf(User2{}),
@@ -205,9 +221,7 @@ func TestProxy_RegisterType(t *testing.T) {
 					TypeMap: make(map[string]reflect.Type),
 					Client:  &http.Client{},
 				},
-				client:       &http.Client{},
-				serializer:   NewSerializerV1(),
-				deserializer: NewDeserializerV1(),
+				client: &http.Client{},
 			},
 			args: args{
 				t: reflect.TypeOf(Account{}),
@@ -219,10 +233,8 @@ func TestProxy_RegisterType(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &Proxy{
-				conf:         tt.fields.conf,
-				client:       tt.fields.client,
-				serializer:   tt.fields.serializer,
-				deserializer: tt.fields.deserializer,
+				conf:   tt.fields.conf,
+				client: tt.fields.client,
 			}
 			if err := c.RegisterType(tt.args.t); (err != nil) != tt.wantErr {
 				t.Errorf("Proxy.RegisterType() error = %v, wantErr %v", err, tt.wantErr)
@@ -261,9 +273,7 @@ func TestNewProxy(t *testing.T) {
 					TypeMap: make(map[string]reflect.Type),
 					Client:  &http.Client{},
 				},
-				client:       &http.Client{},
-				serializer:   NewSerializerV1(),
-				deserializer: NewDeserializerV1(),
+				client: &http.Client{},
 			},
 			wantErr: false,
 		},
@@ -297,9 +307,7 @@ func TestNewProxy(t *testing.T) {
 					TypeMap: make(map[string]reflect.Type),
 					Client:  &http.Client{},
 				},
-				client:       &http.Client{},
-				serializer:   NewSerializerV1(),
-				deserializer: NewDeserializerV1(),
+				client: &http.Client{},
 			},
 			wantErr: false,
 		},
@@ -320,9 +328,7 @@ func TestNewProxy(t *testing.T) {
 					TypeMap: make(map[string]reflect.Type),
 					Client:  &http.Client{},
 				},
-				client:       &http.Client{},
-				serializer:   NewSerializerV1(),
-				deserializer: NewDeserializerV1(),
+				client: &http.Client{},
 			},
 			wantErr: false,
 		},
@@ -366,3 +372,77 @@ func TestNewProxy(t *testing.T) {
 		})
 	}
 }
+
+func TestProxy_InvokeInto(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte{'r', 0x01, 0x00, 'I', 0x00, 0x00, 0x00, 0x0c, 'z'})
+	}))
+	defer srv.Close()
+
+	proxy, err := NewProxy(&ProxyConfig{
+		Version: V1,
+		URL:     srv.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var n int64
+	if err := proxy.InvokeInto(context.Background(), &n, "integer"); err != nil || n != 12 {
+		t.Errorf("Proxy.InvokeInto() = %v, %v", n, err)
+	}
+	if err := proxy.InvokeInto(context.Background(), nil, "integer"); err != nil {
+		t.Errorf("Proxy.InvokeInto() error = %v", err)
+	}
+	var s string
+	if err := proxy.InvokeInto(context.Background(), &s, "integer"); err == nil {
+		t.Errorf("Proxy.InvokeInto() error = %v, wantErr %v", err, true)
+	}
+	if err := proxy.InvokeInto(context.Background(), n, "integer"); err == nil {
+		t.Errorf("Proxy.InvokeInto() error = %v, wantErr %v", err, true)
+	}
+}
+
+func TestProxy_Concurrent(t *testing.T) {
+	s, err := NewServer(serverService{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	proxy, err := NewProxy(&ProxyConfig{Version: V1, URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := proxy.RegisterType(reflect.TypeOf(serverUser{})); err != nil {
+		t.Fatal(err)
+	}
+
+	// calls share the proxy, run with -race
+	var wg sync.WaitGroup
+	errs := make(chan error, 40)
+	for k := 0; k < 20; k++ {
+		wg.Add(2)
+		go func(k int) {
+			defer wg.Done()
+			got, err := proxy.Invoke("Add", int64(k), int64(1))
+			if err != nil || !reflect.DeepEqual(got, []interface{}{int64(k + 1)}) {
+				errs <- fmt.Errorf("Proxy.Invoke() = %v, %v, want %d", got, err, k+1)
+			}
+		}(k)
+		go func(k int) {
+			defer wg.Done()
+			var u serverUser
+			name := fmt.Sprint("user", k)
+			if err := proxy.InvokeInto(context.Background(), &u, "GetUser", name); err != nil || u.Name != name {
+				errs <- fmt.Errorf("Proxy.InvokeInto() = %v, %v, want %s", u, err, name)
+			}
+		}(k)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,210 @@
+package hessian
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// RawMessage a raw encoded hessian value, it is written by SerializerV1 verbatim.
+//
+// As target of Unmarshal, Decoder.Decode and Proxy.InvokeInto, or a field, item or map value of
+// it, RawMessage get the exact bytes of its value, which can be forwarded or decoded later by
+// Unmarshal. Converted from an already decoded value, ex: a Server argument, the value is encoded
+// again.
+type RawMessage []byte
+
+var rawMessageType = reflect.TypeOf(RawMessage(nil))
+
+// rawMessageOf encode decoded value v
+func rawMessageOf(v interface{}) (reflect.Value, error) {
+	o := NewSerializerV1()
+	if err := o.WriteObject(v); err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(RawMessage(o.buf.Bytes())), nil
+}
+
+// readsBytes report whether RawMessage or Unmarshaler can be in a value of type t, which is read
+// from its bytes by readInto
+func readsBytes(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if t == rawMessageType || reflect.PtrTo(t).Implements(unmarshalerType) {
+		return true
+	}
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return readsBytes(t.Elem(), seen)
+	case reflect.Map:
+		return readsBytes(t.Key(), seen) || readsBytes(t.Elem(), seen)
+	case reflect.Struct:
+		for j := 0; j < t.NumField(); j++ {
+			if readsBytes(t.Field(j).Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readInto Read one value from given bytes and begin index into type t, return the value and the
+// index of its last byte. RawMessage in t get the exact bytes of its value, Unmarshaler decode its
+// value by itself, other values are read by ReadValueAt and converted to their types.
+func (i *DeserializerV1) readInto(p []byte, begin int, t reflect.Type) (reflect.Value, int, error) {
+	if t == rawMessageType {
+		_, j, err := i.ReadValueAt(p, begin)
+		if err != nil {
+			return reflect.Value{}, j, err
+		}
+		raw := make(RawMessage, j+1-begin)
+		copy(raw, p[begin:j+1])
+		return reflect.ValueOf(raw), j, nil
+	}
+
+	if t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(unmarshalerType) {
+		_, j, err := i.ReadValueAt(p, begin)
+		if err != nil {
+			return reflect.Value{}, j, err
+		}
+		codec := &Codec{conf: &CodecConfig{Version: V1, TypeMap: i.typeMap, Classes: i.classes}}
+		v, err := unmarshalHessian(p[begin:j+1], t, codec)
+		return v, j, err
+	}
+
+	if begin < len(p) && readsBytes(t, make(map[reflect.Type]bool)) {
+		switch {
+		case p[begin] == 'N':
+			return reflect.Zero(t), begin, nil
+		case t.Kind() == reflect.Ptr:
+			e, j, err := i.readInto(p, begin, t.Elem())
+			if err != nil {
+				return reflect.Value{}, j, err
+			}
+			v := reflect.New(t.Elem())
+			v.Elem().Set(e)
+			return v, j, nil
+		case t.Kind() == reflect.Slice && p[begin] == 'V':
+			return i.readSliceInto(p, begin, t)
+		case (t.Kind() == reflect.Map || t.Kind() == reflect.Struct) && p[begin] == 'M':
+			return i.readMapInto(p, begin, t)
+		}
+	}
+
+	val, j, err := i.ReadValueAt(p, begin)
+	if err != nil {
+		return reflect.Value{}, j, err
+	}
+	v, err := convertValue(val, t)
+	return v, j, err
+}
+
+// readSliceInto Read a list into slice type t.
+//
+// V t b16 b8 <type> l b32 b24 b16 b8 <value> ... z
+func (i *DeserializerV1) readSliceInto(p []byte, begin int, t reflect.Type) (reflect.Value, int, error) {
+	if begin+1 >= len(p) || p[begin+1] != 't' {
+		return reflect.Value{}, begin, fmt.Errorf("parse value: expect 't' after 'V'")
+	}
+	_, j, err := i.ReadStringAt(p, begin+2)
+	if err != nil {
+		return reflect.Value{}, j, err
+	}
+	if j+1 >= len(p) || p[j+1] != 'l' {
+		return reflect.Value{}, j, fmt.Errorf("parse unknowen array length")
+	}
+	l, j, err := i.ReadInt32At(p, j+2)
+	if err != nil {
+		return reflect.Value{}, j, err
+	}
+
+	s := reflect.MakeSlice(t, 0, int(l))
+	for j+1 < len(p) && p[j+1] != 'z' {
+		var e reflect.Value
+		if e, j, err = i.readInto(p, j+1, t.Elem()); err != nil {
+			return reflect.Value{}, j, err
+		}
+		s = reflect.Append(s, e)
+	}
+	if j+1 >= len(p) {
+		return reflect.Value{}, j, fmt.Errorf("parse value: unexpected end")
+	}
+	if s.Len() != int(l) {
+		return reflect.Value{}, j, fmt.Errorf("array length and arguments length not equal %d != %d", l, s.Len())
+	}
+	return s, j + 1, nil
+}
+
+// readMapInto Read a map into map or struct type t, struct fields are matched by hessian tag.
+//
+// M t b16 b8 <type> (<key> <value>) ... z
+func (i *DeserializerV1) readMapInto(p []byte, begin int, t reflect.Type) (reflect.Value, int, error) {
+	if begin+1 >= len(p) || p[begin+1] != 't' {
+		return reflect.Value{}, begin, fmt.Errorf("parse value: expect 't' after 'M'")
+	}
+	_, j, err := i.ReadStringAt(p, begin+2)
+	if err != nil {
+		return reflect.Value{}, j, err
+	}
+
+	v := reflect.New(t).Elem()
+	if t.Kind() == reflect.Map {
+		v = reflect.MakeMap(t)
+	}
+
+	for j+1 < len(p) && p[j+1] != 'z' {
+		if t.Kind() == reflect.Map {
+			var k, e reflect.Value
+			if k, j, err = i.readInto(p, j+1, t.Key()); err != nil {
+				return reflect.Value{}, j, err
+			}
+			if e, j, err = i.readInto(p, j+1, t.Elem()); err != nil {
+				return reflect.Value{}, j, err
+			}
+			v.SetMapIndex(k, e)
+			continue
+		}
+
+		var key interface{}
+		if key, j, err = i.ReadValueAt(p, j+1); err != nil {
+			return reflect.Value{}, j, err
+		}
+		f, ok := fieldByTag(t, key)
+		if !ok {
+			// skip value of unknown field
+			if _, j, err = i.ReadValueAt(p, j+1); err != nil {
+				return reflect.Value{}, j, err
+			}
+			continue
+		}
+		var e reflect.Value
+		if e, j, err = i.readInto(p, j+1, f.Type); err != nil {
+			return reflect.Value{}, j, fmt.Errorf("%s.%s: %v", t, f.Name, err)
+		}
+		v.FieldByIndex(f.Index).Set(e)
+	}
+	if j+1 >= len(p) {
+		return reflect.Value{}, j, fmt.Errorf("parse value: unexpected end")
+	}
+	return v, j + 1, nil
+}
+
+// fieldByTag find exported field of struct type t by hessian tag
+func fieldByTag(t reflect.Type, key interface{}) (reflect.StructField, bool) {
+	name, ok := key.(string)
+	if !ok || len(name) == 0 {
+		return reflect.StructField{}, false
+	}
+	for j := 0; j < t.NumField(); j++ {
+		f := t.Field(j)
+		if f.PkgPath != "" || f.Type == reflect.TypeOf(Package("")) {
+			continue
+		}
+		if f.Tag.Get(tagName) == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,184 @@
+package hessian
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type rawEnvelope struct {
+	Package `hessian:"lab.ggw.Envelope"`
+	Kind    string     `hessian:"kind"`
+	Body    RawMessage `hessian:"body"`
+}
+
+type rawNode struct {
+	Next  *rawNode   `hessian:"next"`
+	Items []rawNode  `hessian:"items"`
+	Raw   RawMessage `hessian:"raw"`
+}
+
+// rawBody Mt lab.ggw.User name: "a" z
+var rawBody = []byte{
+	'M', 't', 0, 12, 'l', 'a', 'b', '.', 'g', 'g', 'w', '.', 'U', 's', 'e', 'r',
+	'S', 0, 4, 'n', 'a', 'm', 'e', 'S', 0, 1, 'a',
+	'z',
+}
+
+func TestReadsBytes(t *testing.T) {
+	tests := []struct {
+		name string
+		t    reflect.Type
+		want bool
+	}{
+		{name: "raw", t: rawMessageType, want: true},
+		{name: "bytes", t: reflect.TypeOf([]byte(nil)), want: false},
+		{name: "struct", t: reflect.TypeOf(rawEnvelope{}), want: true},
+		{name: "map", t: reflect.TypeOf(map[string]RawMessage{}), want: true},
+		{name: "recursive", t: reflect.TypeOf(&rawNode{}), want: true},
+		{name: "user", t: reflect.TypeOf(&marshalUser{}), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := readsBytes(tt.t, make(map[reflect.Type]bool)); got != tt.want {
+				t.Errorf("readsBytes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRawMessage_Marshal(t *testing.T) {
+	p, err := Marshal(&rawEnvelope{Kind: "user", Body: rawBody})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(p, rawBody) {
+		t.Errorf("Marshal() = %q, want raw body %q", p, rawBody)
+	}
+
+	tests := []struct {
+		name string
+		v    interface{}
+		want []byte
+	}{
+		{name: "raw", v: RawMessage(rawBody), want: rawBody},
+		{name: "empty", v: RawMessage(nil), want: []byte{'N'}},
+		{name: "ptr", v: &RawMessage{'T'}, want: []byte{'T'}},
+		{name: "nil ptr", v: (*RawMessage)(nil), want: []byte{'N'}},
+		{name: "list", v: []RawMessage{{'T'}, {'F'}}, want: []byte{'V', 't', 0, 7, '[', 'o', 'b', 'j', 'e', 'c', 't', 'l', 0, 0, 0, 2, 'T', 'F', 'z'}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Marshal() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRawMessage_Unmarshal(t *testing.T) {
+	p, err := Marshal(&rawEnvelope{Kind: "user", Body: rawBody})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var env rawEnvelope
+	if err := Unmarshal(p, &env); err != nil {
+		t.Fatal(err)
+	}
+	if env.Kind != "user" || !bytes.Equal(env.Body, rawBody) {
+		t.Errorf("Unmarshal() = %+v, want body %q", env, rawBody)
+	}
+
+	// decode later against a type chosen at runtime
+	var u marshalUser
+	if err := Unmarshal(env.Body, &u); err != nil || u.Name != "a" {
+		t.Errorf("Unmarshal() = %+v, %v", u, err)
+	}
+
+	var raw RawMessage
+	if err := Unmarshal(p, &raw); err != nil || !bytes.Equal(raw, p) {
+		t.Errorf("Unmarshal() = %q, %v, want %q", raw, err, p)
+	}
+
+	var list []RawMessage
+	if err := Unmarshal([]byte{'V', 't', 0, 0, 'l', 0, 0, 0, 2, 'I', 0, 0, 0, 1, 'N', 'z'}, &list); err != nil {
+		t.Fatal(err)
+	}
+	if want := []RawMessage{{'I', 0, 0, 0, 1}, {'N'}}; !reflect.DeepEqual(list, want) {
+		t.Errorf("Unmarshal() = %q, want %q", list, want)
+	}
+
+	var m map[string]RawMessage
+	if err := Unmarshal(rawBody, &m); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]RawMessage{"name": {'S', 0, 1, 'a'}}; !reflect.DeepEqual(m, want) {
+		t.Errorf("Unmarshal() = %q, want %q", m, want)
+	}
+
+	var node *rawNode
+	if err := Unmarshal([]byte{'N'}, &node); err != nil || node != nil {
+		t.Errorf("Unmarshal() = %v, %v, want nil", node, err)
+	}
+}
+
+func TestRawMessage_ConvertValue(t *testing.T) {
+	got, err := convertValue("a", rawMessageType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (RawMessage{'S', 0, 1, 'a'}); !reflect.DeepEqual(got.Interface(), want) {
+		t.Errorf("convertValue() = %q, want %q", got.Interface(), want)
+	}
+}
+
+func TestProxy_InvokeInto_RawMessage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		o := NewSerializerV1()
+		if r.Header.Get("X-Fault") != "" {
+			o.WriteFault("ServiceException", "boom", nil)
+			w.Write(o.buf.Bytes())
+			return
+		}
+		o.WriteReply(&rawEnvelope{Kind: "user", Body: rawBody}, map[string]interface{}{"trace": "abc"})
+		w.Write(o.buf.Bytes())
+	}))
+	defer srv.Close()
+
+	proxy, err := NewProxy(&ProxyConfig{Version: V1, URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var env rawEnvelope
+	if err := proxy.InvokeInto(context.Background(), &env, "get"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(env.Body, rawBody) {
+		t.Errorf("Proxy.InvokeInto() body = %q, want %q", env.Body, rawBody)
+	}
+
+	// forward it verbatim
+	var raw RawMessage
+	if err := proxy.InvokeInto(context.Background(), &raw, "get"); err != nil {
+		t.Fatal(err)
+	}
+	p, _ := Marshal(&rawEnvelope{Kind: "user", Body: rawBody})
+	if len(raw) != len(p) || !bytes.Contains(raw, rawBody) {
+		t.Errorf("Proxy.InvokeInto() = %q, want %q", raw, p)
+	}
+
+	proxy, _ = NewProxy(&ProxyConfig{Version: V1, URL: srv.URL, Header: http.Header{"X-Fault": {"1"}}})
+	err = proxy.InvokeInto(context.Background(), &raw, "get")
+	if f, ok := err.(*Fault); !ok || f.Code != "ServiceException" {
+		t.Errorf("Proxy.InvokeInto() error = %v, want ServiceException", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"math"
 	"reflect"
+	"sort"
 	"time"
 )
 
@@ -16,6 +17,9 @@ type SerializerV1 struct {
 	version int
 	buf     *bytes.Buffer
 	typeMap map[string]reflect.Type
+	classes *ClassRegistry
+	// intPolicy how go int and uint are written
+	intPolicy intPolicy
 }
 
 // Writes a string value to the stream using UTF-8 encoding.
@@ -90,9 +94,9 @@ func (o *SerializerV1) Call(m string, args ...interface{}) error {
 	if err := o.WriteMethod(m); err != nil {
 		return err
 	}
-	for _, arg := range args {
+	for i, arg := range args {
 		if err := o.WriteObject(arg); err != nil {
-			return err
+			return withPath(err, fmt.Sprintf("args[%d]", i))
 		}
 	}
 	if err := o.CompleteCall(); err != nil {
@@ -123,6 +127,91 @@ func (o *SerializerV1) CompleteCall() error {
 	return nil
 }
 
+// WriteReply Writes a complete reply, header names are written in order.
+//
+// r x01 x00 (H b16 b8 <header-name> <value>)* <value> z
+func (o *SerializerV1) WriteReply(v interface{}, headers map[string]interface{}) error {
+	if err := o.startReply(); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := o.buf.WriteByte('H'); err != nil {
+			return err
+		}
+		o.printString(name)
+		if err := o.WriteObject(headers[name]); err != nil {
+			return err
+		}
+	}
+
+	if err := o.WriteObject(v); err != nil {
+		return err
+	}
+	if err := o.buf.WriteByte('z'); err != nil {
+		return err
+	}
+	return nil
+}
+
+// WriteFault Writes a complete fault reply, detail is omitted if it is nil.
+//
+// r x01 x00 f S code <code> S message <message> [S detail <detail>] z
+func (o *SerializerV1) WriteFault(code, message string, detail interface{}) error {
+	if err := o.startReply(); err != nil {
+		return err
+	}
+	if err := o.buf.WriteByte('f'); err != nil {
+		return err
+	}
+
+	if err := o.WriteString("code"); err != nil {
+		return err
+	}
+	if err := o.WriteString(code); err != nil {
+		return err
+	}
+	if err := o.WriteString("message"); err != nil {
+		return err
+	}
+	if err := o.WriteString(message); err != nil {
+		return err
+	}
+	if detail != nil {
+		if err := o.WriteString("detail"); err != nil {
+			return err
+		}
+		if err := o.WriteObject(detail); err != nil {
+			return err
+		}
+	}
+
+	if err := o.buf.WriteByte('z'); err != nil {
+		return err
+	}
+	return nil
+}
+
+// startReply Starts the reply.
+func (o *SerializerV1) startReply() error {
+	if err := o.buf.WriteByte('r'); err != nil {
+		return err
+	}
+	if err := o.buf.WriteByte(byte(o.version)); err != nil {
+		return err
+	}
+	if err := o.buf.WriteByte(byte(0)); err != nil {
+		return err
+	}
+	return nil
+}
+
 // WriteMethod Writes the method tag.
 //
 // m b16 b8 method-name
@@ -143,42 +232,85 @@ func (o *SerializerV1) WriteObject(arg interface{}) error {
 		}
 		return nil
 	}
+	if m, ok := arg.(Marshaler); ok {
+		if rv := reflect.ValueOf(arg); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return o.WriteNull()
+		}
+		codec := &Codec{conf: &CodecConfig{Version: V1, TypeMap: o.typeMap, Classes: o.classes, IntPolicy: o.intPolicy}}
+		return m.MarshalHessian(codec.NewEncoder(o.buf))
+	}
+
+	if ok, err := o.writeClass(arg); ok {
+		return err
+	}
+
+	switch v := arg.(type) {
+	case TypedMap:
+		return o.WriteTypedMap(v)
+	case *TypedMap:
+		if v == nil {
+			return o.WriteNull()
+		}
+		return o.WriteTypedMap(*v)
+	case TypedList:
+		return o.WriteTypedList(v)
+	case *TypedList:
+		if v == nil {
+			return o.WriteNull()
+		}
+		return o.WriteTypedList(*v)
+	case RawMessage:
+		if len(v) == 0 {
+			return o.WriteNull()
+		}
+		_, err := o.buf.Write(v)
+		return err
+	case *RawMessage:
+		if v == nil {
+			return o.WriteNull()
+		}
+		return o.WriteObject(*v)
+	}
+
+	rv := reflect.ValueOf(arg)
 	switch t.Kind() {
 	default:
+		return &UnsupportedTypeError{Type: t}
 	case reflect.String:
-		if err := o.WriteString(arg.(string)); err != nil {
+		if err := o.WriteString(rv.String()); err != nil {
 			return err
 		}
 	case reflect.Bool:
-		if err := o.WriteBool(arg.(bool)); err != nil {
+		if err := o.WriteBool(rv.Bool()); err != nil {
 			return err
 		}
-	case reflect.Int:
-		if err := o.WriteInt(int32(arg.(int))); err != nil {
+	case reflect.Int, reflect.Uint:
+		if err := o.writeInteger(rv, intsAsLong(o.intPolicy, rv)); err != nil {
 			return err
 		}
-	case reflect.Int8:
-		if err := o.WriteInt(int32(arg.(int8))); err != nil {
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		if err := o.WriteInt(int32(rv.Int())); err != nil {
 			return err
 		}
-	case reflect.Int16:
-		if err := o.WriteInt(int32(arg.(int16))); err != nil {
+	case reflect.Int64:
+		if err := o.WriteLong(rv.Int()); err != nil {
 			return err
 		}
-	case reflect.Int32:
-		if err := o.WriteInt(int32(arg.(int32))); err != nil {
+	case reflect.Uint8, reflect.Uint16:
+		// fit in int
+		if err := o.WriteInt(int32(rv.Uint())); err != nil {
 			return err
 		}
-	case reflect.Int64:
-		if err := o.WriteLong(arg.(int64)); err != nil {
-			return err
+	case reflect.Uint32, reflect.Uint64:
+		// java has no unsigned types, written as long if not overflow
+		if rv.Uint() > math.MaxInt64 {
+			return &UnsupportedValueError{Value: arg, Reason: "overflows long"}
 		}
-	case reflect.Float32:
-		if err := o.WriteDouble(float64(arg.(float32))); err != nil {
+		if err := o.WriteLong(int64(rv.Uint())); err != nil {
 			return err
 		}
-	case reflect.Float64:
-		if err := o.WriteDouble(arg.(float64)); err != nil {
+	case reflect.Float32, reflect.Float64:
+		if err := o.WriteDouble(rv.Float()); err != nil {
 			return err
 		}
 	case reflect.Map:
@@ -196,6 +328,13 @@ func (o *SerializerV1) WriteObject(arg interface{}) error {
 			return err
 		}
 	case reflect.Ptr:
+		if t.Elem().Kind() != reflect.Struct {
+			// pointer to other types is written as its element
+			if rv.IsNil() {
+				return o.WriteNull()
+			}
+			return o.WriteObject(rv.Elem().Interface())
+		}
 		if err := o.WritePtr(arg); err != nil {
 			return err
 		}
@@ -354,10 +493,10 @@ func (o *SerializerV1) WriteMap(m interface{}) error {
 	v := reflect.ValueOf(m)
 	for _, key := range v.MapKeys() {
 		if err := o.WriteObject(key.Interface()); err != nil {
-			return err
+			return withPath(err, fmt.Sprintf("[%v]", key.Interface()))
 		}
 		if err := o.WriteObject(v.MapIndex(key).Interface()); err != nil {
-			return err
+			return withPath(err, fmt.Sprintf("[%v]", key.Interface()))
 		}
 	}
 
@@ -378,7 +517,10 @@ func (o *SerializerV1) WriteArray(arr interface{}) error {
 		return fmt.Errorf("WriteArray input is not a array or slice")
 	}
 	if t.Elem().Kind() == reflect.Uint8 {
-		return o.WriteBytes(arr.([]byte))
+		// byte slices and arrays of any named type
+		b := make([]byte, reflect.ValueOf(arr).Len())
+		reflect.Copy(reflect.ValueOf(b), reflect.ValueOf(arr))
+		return o.WriteBytes(b)
 	}
 
 	// write begin
@@ -391,47 +533,133 @@ func (o *SerializerV1) WriteArray(arr interface{}) error {
 
 	v := reflect.ValueOf(arr)
 
-	var arrtype string
-	// define array type
+	o.printString(listType(v, o.intPolicy, o.classes))
+
+	if err := o.buf.WriteByte('l'); err != nil {
+		return err
+	}
+	o.printInt32(int32(v.Len()))
+
+	if k := t.Elem().Kind(); (k == reflect.Int || k == reflect.Uint) && !customType(t.Elem(), o.classes) {
+		// all items as the list type
+		long := intsAsLong(o.intPolicy, v)
+		for i := 0; i < v.Len(); i++ {
+			if err := o.writeInteger(v.Index(i), long); err != nil {
+				return withPath(err, fmt.Sprintf("[%d]", i))
+			}
+		}
+	} else {
+		for i := 0; i < v.Len(); i++ {
+			if err := o.WriteObject(v.Index(i).Interface()); err != nil {
+				return withPath(err, fmt.Sprintf("[%d]", i))
+			}
+		}
+	}
+
+	if err := o.buf.WriteByte('z'); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// listType the list type written for array or slice v, go int and uint items are written by policy p,
+// items of custom types of r are objects
+func listType(v reflect.Value, p intPolicy, r *ClassRegistry) string {
+	t := v.Type()
+	if customType(t.Elem(), r) {
+		// written by the type itself or its class codec
+		return "[object"
+	}
 	switch t.Elem().Kind() {
 	default:
-		arrtype = "[object"
-	case reflect.Interface:
-		arrtype = "[object"
+		return "[object"
 	case reflect.String:
-		arrtype = "[string"
-	case reflect.Int:
-		arrtype = "[int"
-	case reflect.Int8:
-		arrtype = "[int"
-	case reflect.Int16:
-		arrtype = "[int"
-	case reflect.Int32:
-		arrtype = "[int"
-	case reflect.Int64:
-		arrtype = "[long"
-	case reflect.Float32:
-		arrtype = "[double"
-	case reflect.Float64:
-		arrtype = "[double"
+		return "[string"
+	case reflect.Int, reflect.Uint:
+		if intsAsLong(p, v) {
+			return "[long"
+		}
+		return "[int"
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint16:
+		return "[int"
+	case reflect.Int64, reflect.Uint32, reflect.Uint64:
+		return "[long"
+	case reflect.Float32, reflect.Float64:
+		return "[double"
 	}
-	o.printString(arrtype)
+}
 
-	if err := o.buf.WriteByte('l'); err != nil {
+// customType true if values of t are written by Marshaler or a class codec of r instead of their kind,
+// the pointer of a registered type and the value of a registered pointer type included as writeClass
+func customType(t reflect.Type, r *ClassRegistry) bool {
+	if t.Implements(marshalerType) {
+		return true
+	}
+	if _, ok := r.byType(t); ok {
+		return true
+	}
+	if t.Kind() == reflect.Ptr {
+		_, ok := r.byType(t.Elem())
+		return ok
+	}
+	_, ok := r.byType(reflect.PtrTo(t))
+	return ok
+}
+
+// WriteTypedMap Write a typed map to the stream, entries are written in order. The map will be written with the following syntax:
+//
+// Mt b16 b8 <type> (<key> <value>)z
+func (o *SerializerV1) WriteTypedMap(m TypedMap) error {
+	if err := o.buf.WriteByte('M'); err != nil {
 		return err
 	}
-	o.printInt32(int32(v.Len()))
+	if err := o.buf.WriteByte('t'); err != nil {
+		return err
+	}
+	o.printString(m.Type)
 
-	for i := 0; i < v.Len(); i++ {
-		if err := o.WriteObject(v.Index(i).Interface()); err != nil {
-			return err
+	for _, e := range m.Entries {
+		if err := o.WriteObject(e.Key); err != nil {
+			return withPath(err, fmt.Sprintf("[%v]", e.Key))
+		}
+		if err := o.WriteObject(e.Value); err != nil {
+			return withPath(err, fmt.Sprintf("[%v]", e.Key))
 		}
 	}
 
 	if err := o.buf.WriteByte('z'); err != nil {
 		return err
 	}
+	return nil
+}
 
+// WriteTypedList Write a typed list to the stream. The list will be written with the following syntax:
+//
+// Vt b16 b8 <type> l b32 b24 b16 b8 <object> ... ... z
+func (o *SerializerV1) WriteTypedList(l TypedList) error {
+	if err := o.buf.WriteByte('V'); err != nil {
+		return err
+	}
+	if err := o.buf.WriteByte('t'); err != nil {
+		return err
+	}
+	o.printString(l.Type)
+
+	if err := o.buf.WriteByte('l'); err != nil {
+		return err
+	}
+	o.printInt32(int32(len(l.Items)))
+
+	for i, item := range l.Items {
+		if err := o.WriteObject(item); err != nil {
+			return withPath(err, fmt.Sprintf("[%d]", i))
+		}
+	}
+
+	if err := o.buf.WriteByte('z'); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -441,6 +669,9 @@ func (o *SerializerV1) WriteStruct(s interface{}) error {
 	if t.Kind() != reflect.Struct {
 		return fmt.Errorf("WriteStruct input is not a struct")
 	}
+	if ok, err := o.writeClass(s); ok {
+		return err
+	}
 	if err := o.buf.WriteByte('M'); err != nil {
 		return err
 	}
@@ -452,11 +683,18 @@ func (o *SerializerV1) WriteStruct(s interface{}) error {
 
 	var pkg string
 	m := make(map[string]interface{})
+	names := make(map[string]string)
+	// fields are written in declaration order
+	var keys []string
 	for i, l := 0, v.NumField(); i < l; i++ {
 		if t.Field(i).Type == reflect.TypeOf(Package("")) {
 			pkg = t.Field(i).Tag.Get(tagName)
 			continue
 		}
+		if _, ok := m[t.Field(i).Tag.Get(tagName)]; !ok {
+			keys = append(keys, t.Field(i).Tag.Get(tagName))
+		}
+		names[t.Field(i).Tag.Get(tagName)] = t.Field(i).Name
 		if t.Field(i).Type.Kind() == reflect.Ptr && v.Field(i).IsNil() {
 			m[t.Field(i).Tag.Get(tagName)] = nil
 			continue
@@ -466,15 +704,15 @@ func (o *SerializerV1) WriteStruct(s interface{}) error {
 
 	o.printString(pkg)
 
-	for k, v := range m {
+	for _, k := range keys {
 		if len(k) == 0 {
 			continue
 		}
 		if err := o.WriteObject(k); err != nil {
 			return err
 		}
-		if err := o.WriteObject(v); err != nil {
-			return err
+		if err := o.WriteObject(m[k]); err != nil {
+			return withPath(err, names[k])
 		}
 	}
 
@@ -493,6 +731,9 @@ func (o *SerializerV1) WritePtr(p interface{}) error {
 	if t.Elem().Kind() != reflect.Struct {
 		return fmt.Errorf("WritePtr input is not a struct pointer")
 	}
+	if reflect.ValueOf(p).IsNil() {
+		return o.WriteNull()
+	}
 	return o.WriteStruct(reflect.ValueOf(p).Elem().Interface())
 }
 
@@ -522,5 +763,6 @@ func NewSerializerV1() *SerializerV1 {
 		buf:     bytes.NewBuffer(nil),
 		version: 1,
 		typeMap: make(map[string]reflect.Type),
+		classes: DefaultClassRegistry,
 	}
 }
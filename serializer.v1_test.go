@@ -1498,6 +1498,93 @@ func TestSerializerV1_WriteStruct(t *testing.T) {
 	}
 }
 
+func TestSerializerV1_WriteTypedMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       TypedMap
+		wantErr bool
+		wantBuf []byte
+	}{
+		{
+			name: "typed",
+			m: TypedMap{
+				Type: "a.User",
+				Entries: []MapEntry{
+					{Key: "name", Value: "ggw"},
+					{Key: "age", Value: int32(1)},
+				},
+			},
+			wantErr: false,
+			wantBuf: []byte{
+				'M', 't', 0x00, 0x06, 'a', '.', 'U', 's', 'e', 'r',
+				'S', 0x00, 0x04, 'n', 'a', 'm', 'e', 'S', 0x00, 0x03, 'g', 'g', 'w',
+				'S', 0x00, 0x03, 'a', 'g', 'e', 'I', 0x00, 0x00, 0x00, 0x01,
+				'z',
+			},
+		},
+		{
+			name:    "untyped",
+			m:       TypedMap{},
+			wantErr: false,
+			wantBuf: []byte{'M', 't', 0x00, 0x00, 'z'},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewSerializerV1()
+			if err := o.WriteObject(tt.m); (err != nil) != tt.wantErr {
+				t.Errorf("SerializerV1.WriteTypedMap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			b, _ := ioutil.ReadAll(o.Reader())
+			if !reflect.DeepEqual(tt.wantBuf, b) {
+				t.Errorf("SerializerV1.WriteTypedMap() content is %v, wantBuf %v", b, tt.wantBuf)
+			}
+		})
+	}
+}
+
+func TestSerializerV1_WriteTypedList(t *testing.T) {
+	tests := []struct {
+		name    string
+		l       interface{}
+		wantErr bool
+		wantBuf []byte
+	}{
+		{
+			name: "typed",
+			l: TypedList{
+				Type:  "[int",
+				Items: []interface{}{int32(1)},
+			},
+			wantErr: false,
+			wantBuf: []byte{
+				'V', 't', 0x00, 0x04, '[', 'i', 'n', 't',
+				'l', 0x00, 0x00, 0x00, 0x01,
+				'I', 0x00, 0x00, 0x00, 0x01,
+				'z',
+			},
+		},
+		{
+			name:    "ptr",
+			l:       &TypedList{},
+			wantErr: false,
+			wantBuf: []byte{'V', 't', 0x00, 0x00, 'l', 0x00, 0x00, 0x00, 0x00, 'z'},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewSerializerV1()
+			if err := o.WriteObject(tt.l); (err != nil) != tt.wantErr {
+				t.Errorf("SerializerV1.WriteTypedList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			b, _ := ioutil.ReadAll(o.Reader())
+			if !reflect.DeepEqual(tt.wantBuf, b) {
+				t.Errorf("SerializerV1.WriteTypedList() content is %v, wantBuf %v", b, tt.wantBuf)
+			}
+		})
+	}
+}
+
 func TestSerializerV1_WritePtr(t *testing.T) {
 	type User struct {
 		Package `hessian:"lab.ggw.shs.User"`
@@ -1759,6 +1846,7 @@ func TestNewSerializerV1(t *testing.T) {
 				version: 1,
 				buf:     bytes.NewBuffer(nil),
 				typeMap: make(map[string]reflect.Type),
+				classes: DefaultClassRegistry,
 			},
 		},
 	}
@@ -1770,3 +1858,192 @@ func TestNewSerializerV1(t *testing.T) {
 		})
 	}
 }
+
+func TestSerializerV1_WriteReply(t *testing.T) {
+	tests := []struct {
+		name        string
+		v           interface{}
+		headers     map[string]interface{}
+		wantBuf     []byte
+		wantHeaders map[string]interface{}
+	}{
+		{
+			name:    "value",
+			v:       int32(12),
+			wantBuf: []byte{'r', 0x01, 0x00, 'I', 0x00, 0x00, 0x00, 0x0c, 'z'},
+		},
+		{
+			name:    "null",
+			v:       nil,
+			wantBuf: []byte{'r', 0x01, 0x00, 'N', 'z'},
+		},
+		{
+			name:    "headers",
+			v:       "ok",
+			headers: map[string]interface{}{"b": true, "a": int64(1)},
+			wantBuf: []byte{
+				'r', 0x01, 0x00,
+				'H', 0x00, 0x01, 'a', 'L', 0, 0, 0, 0, 0, 0, 0, 1,
+				'H', 0x00, 0x01, 'b', 'T',
+				'S', 0x00, 0x02, 'o', 'k',
+				'z',
+			},
+			wantHeaders: map[string]interface{}{"b": true, "a": int64(1)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewSerializerV1()
+			if err := o.WriteReply(tt.v, tt.headers); err != nil {
+				t.Fatalf("SerializerV1.WriteReply() error = %v", err)
+			}
+			b, _ := ioutil.ReadAll(o.Reader())
+			if !reflect.DeepEqual(tt.wantBuf, b) {
+				t.Errorf("SerializerV1.WriteReply() content is %v, wantBuf %v", b, tt.wantBuf)
+			}
+
+			got, headers, idx, err := NewDeserializerV1().ReadReplyAt(b, 0)
+			if err != nil {
+				t.Fatalf("DeserializerV1.ReadReplyAt() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.v) || !reflect.DeepEqual(headers, tt.wantHeaders) || idx != len(b)-1 {
+				t.Errorf("DeserializerV1.ReadReplyAt() = %v, %v, %v, want %v, %v, %v", got, headers, idx, tt.v, tt.wantHeaders, len(b)-1)
+			}
+		})
+	}
+}
+
+func TestSerializerV1_WriteFault(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		message string
+		detail  interface{}
+		wantBuf []byte
+		want    *Fault
+	}{
+		{
+			name:    "fault",
+			code:    "ServiceException",
+			message: "boom",
+			wantBuf: []byte{
+				'r', 0x01, 0x00, 'f',
+				'S', 0x00, 0x04, 'c', 'o', 'd', 'e',
+				'S', 0x00, 0x10, 'S', 'e', 'r', 'v', 'i', 'c', 'e', 'E', 'x', 'c', 'e', 'p', 't', 'i', 'o', 'n',
+				'S', 0x00, 0x07, 'm', 'e', 's', 's', 'a', 'g', 'e',
+				'S', 0x00, 0x04, 'b', 'o', 'o', 'm',
+				'z',
+			},
+			want: &Fault{Code: "ServiceException", Message: "boom"},
+		},
+		{
+			name:    "detail",
+			code:    "ServiceException",
+			message: "",
+			detail:  TypedMap{Type: "E", Entries: []MapEntry{{Key: "detailMessage", Value: "x"}}},
+			wantBuf: []byte{
+				'r', 0x01, 0x00, 'f',
+				'S', 0x00, 0x04, 'c', 'o', 'd', 'e',
+				'S', 0x00, 0x10, 'S', 'e', 'r', 'v', 'i', 'c', 'e', 'E', 'x', 'c', 'e', 'p', 't', 'i', 'o', 'n',
+				'S', 0x00, 0x07, 'm', 'e', 's', 's', 'a', 'g', 'e',
+				'S', 0x00, 0x00,
+				'S', 0x00, 0x06, 'd', 'e', 't', 'a', 'i', 'l',
+				'M', 't', 0x00, 0x01, 'E',
+				'S', 0x00, 0x0d, 'd', 'e', 't', 'a', 'i', 'l', 'M', 'e', 's', 's', 'a', 'g', 'e',
+				'S', 0x00, 0x01, 'x',
+				'z',
+				'z',
+			},
+			want: &Fault{Code: "ServiceException", Detail: map[interface{}]interface{}{"detailMessage": "x"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewSerializerV1()
+			if err := o.WriteFault(tt.code, tt.message, tt.detail); err != nil {
+				t.Fatalf("SerializerV1.WriteFault() error = %v", err)
+			}
+			b, _ := ioutil.ReadAll(o.Reader())
+			if !reflect.DeepEqual(tt.wantBuf, b) {
+				t.Errorf("SerializerV1.WriteFault() content is %v, wantBuf %v", b, tt.wantBuf)
+			}
+
+			_, _, idx, err := NewDeserializerV1().ReadReplyAt(b, 0)
+			if !reflect.DeepEqual(err, tt.want) || idx != len(b)-1 {
+				t.Errorf("DeserializerV1.ReadReplyAt() error = %#v, %v, want %#v, %v", err, idx, tt.want, len(b)-1)
+			}
+		})
+	}
+}
+
+func TestSerializerV1_WriteObject_Kinds(t *testing.T) {
+	type level uint8
+	type code string
+	type item struct {
+		Package `hessian:"lab.ggw.Item"`
+		Done    chan bool `hessian:"done"`
+	}
+	n := int32(7)
+	tests := []struct {
+		name     string
+		arg      interface{}
+		wantBuf  []byte
+		wantPath string
+		wantErr  bool
+	}{
+		{name: "uint8", arg: uint8(255), wantBuf: []byte{'I', 0, 0, 0, 0xff}},
+		{name: "uint16", arg: uint16(65535), wantBuf: []byte{'I', 0, 0, 0xff, 0xff}},
+		{name: "uint32", arg: uint32(math.MaxUint32), wantBuf: []byte{'L', 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff}},
+		{name: "uint64", arg: uint64(math.MaxInt64), wantBuf: []byte{'L', 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{name: "uint", arg: uint(1), wantBuf: []byte{'I', 0, 0, 0, 1}},
+		{name: "uint64 overflow", arg: uint64(math.MaxInt64 + 1), wantErr: true},
+		{name: "named uint8", arg: level(1), wantBuf: []byte{'I', 0, 0, 0, 1}},
+		{name: "named string", arg: code("a"), wantBuf: []byte{'S', 0, 1, 'a'}},
+		{name: "byte array", arg: [2]byte{1, 2}, wantBuf: []byte{'B', 0, 2, 1, 2}},
+		{name: "uint32 slice", arg: []uint32{1}, wantBuf: []byte{'V', 't', 0, 5, '[', 'l', 'o', 'n', 'g', 'l', 0, 0, 0, 1, 'L', 0, 0, 0, 0, 0, 0, 0, 1, 'z'}},
+		{name: "int pointer", arg: &n, wantBuf: []byte{'I', 0, 0, 0, 7}},
+		{name: "nil int pointer", arg: (*int32)(nil), wantBuf: []byte{'N'}},
+		{name: "chan", arg: make(chan int), wantErr: true},
+		{name: "func", arg: func() {}, wantErr: true},
+		{name: "complex", arg: complex(1, 2), wantErr: true},
+		{name: "uintptr", arg: uintptr(1), wantErr: true},
+		{name: "struct field", arg: item{}, wantPath: "Done", wantErr: true},
+		{name: "slice of struct", arg: []item{{}}, wantPath: "[0].Done", wantErr: true},
+		{name: "map value", arg: map[string]interface{}{"a": []interface{}{1, func() {}}}, wantPath: "[a][1]", wantErr: true},
+		{name: "map key", arg: map[interface{}]int{complex(1, 0): 1}, wantPath: "[(1+0i)]", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewSerializerV1()
+			err := o.WriteObject(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SerializerV1.WriteObject() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				var path string
+				switch e := err.(type) {
+				case *UnsupportedTypeError:
+					path = e.Path
+				case *UnsupportedValueError:
+					path = e.Path
+				default:
+					t.Errorf("SerializerV1.WriteObject() error = %T, want unsupported error", err)
+				}
+				if path != tt.wantPath {
+					t.Errorf("SerializerV1.WriteObject() error path = %q, want %q", path, tt.wantPath)
+				}
+				return
+			}
+			if b := o.buf.Bytes(); !reflect.DeepEqual(b, tt.wantBuf) {
+				t.Errorf("SerializerV1.WriteObject() content is %v, wantBuf %v", b, tt.wantBuf)
+			}
+		})
+	}
+
+	// arguments of call
+	err := NewSerializerV1().Call("add", 1, []interface{}{make(chan int)})
+	if e, ok := err.(*UnsupportedTypeError); !ok || e.Path != "args[1][0]" || e.Type != reflect.TypeOf(make(chan int)) {
+		t.Errorf("SerializerV1.Call() error = %v, want unsupported chan int at args[1][0]", err)
+	}
+}
@@ -0,0 +1,257 @@
+package hessian
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"reflect"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Server an http.Handler serve hessian 1.0 calls by the exported methods of a service.
+//
+// A call of "getUser" dispatch to method GetUser, mangled names like getUser__1 and getUser_long
+// are also accepted. The method may take a context.Context first, which is the request context,
+// and return nothing, a value, an error, or a value and an error. Arguments are converted to the
+// parameter types, a returned error is replied as a fault mapped by SetFaultFunc or FaultError,
+// default ServiceException. A panic is recovered and replied as ServiceException.
+type Server struct {
+	methods   map[string]reflect.Value
+	faultFunc func(error) *Fault
+	intPolicy intPolicy
+}
+
+// NewServer create a hessian server of the service
+func NewServer(service interface{}) (*Server, error) {
+	if service == nil {
+		return nil, fmt.Errorf("NewServer input service is nil")
+	}
+
+	v := reflect.ValueOf(service)
+	t := v.Type()
+	s := &Server{methods: make(map[string]reflect.Value)}
+
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		if len(m.PkgPath) > 0 {
+			continue
+		}
+		if err := checkServerMethod(m.Type); err != nil {
+			return nil, fmt.Errorf("method %s: %v", m.Name, err)
+		}
+		s.methods[m.Name] = v.Method(i)
+	}
+
+	if len(s.methods) == 0 {
+		return nil, fmt.Errorf("NewServer input %s has no exported methods", t)
+	}
+	return s, nil
+}
+
+// checkServerMethod check results of method type, the receiver is the first input
+func checkServerMethod(t reflect.Type) error {
+	if t.IsVariadic() {
+		return fmt.Errorf("variadic method is unsupported")
+	}
+	switch t.NumOut() {
+	case 0, 1:
+	case 2:
+		if t.Out(1) != errorType {
+			return fmt.Errorf("second result must be error")
+		}
+	default:
+		return fmt.Errorf("too many results")
+	}
+	return nil
+}
+
+// lookup find method by java name, mangled name is stripped when the method is not found
+func (s *Server) lookup(name string, args int) (reflect.Value, bool) {
+	candidates := []string{name}
+	if i := strings.Index(name, "__"); i > 0 {
+		// arity mangling, ex: add__2
+		if n, err := strconv.Atoi(name[i+2:]); err == nil && n == args {
+			candidates = append(candidates, name[:i])
+		}
+	} else if i := strings.Index(name, "_"); i > 0 && strings.Count(name, "_") == args {
+		// type mangling, ex: add_int_int
+		candidates = append(candidates, name[:i])
+	}
+
+	for _, c := range candidates {
+		if m, ok := s.methods[c]; ok {
+			return m, true
+		}
+		r, size := utf8.DecodeRuneInString(c)
+		if m, ok := s.methods[string(unicode.ToUpper(r))+c[size:]]; ok {
+			return m, true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Hessian requires POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	m, args, err := NewDeserializerV1().ReadRequest(r)
+	if err != nil {
+		writeServerFault(w, &Fault{Code: "ProtocolException", Message: err.Error()})
+		return
+	}
+
+	method, ok := s.lookup(m, len(args))
+	if !ok {
+		writeServerFault(w, &Fault{Code: "NoSuchMethodException", Message: fmt.Sprintf("The service has no method named: %s", m)})
+		return
+	}
+
+	in, err := serverArgs(r.Context(), method.Type(), args)
+	if err != nil {
+		writeServerFault(w, &Fault{Code: "ProtocolException", Message: fmt.Sprintf("%s: %v", m, err)})
+		return
+	}
+
+	out, f := s.call(m, method, in)
+	if f != nil {
+		writeServerFault(w, f)
+		return
+	}
+
+	var reply interface{}
+	switch len(out) {
+	case 1:
+		if method.Type().Out(0) == errorType {
+			err, _ = out[0].Interface().(error)
+		} else {
+			reply = out[0].Interface()
+		}
+	case 2:
+		reply = out[0].Interface()
+		err, _ = out[1].Interface().(error)
+	}
+	if err != nil {
+		writeServerFault(w, s.fault(err))
+		return
+	}
+
+	writeServerReply(w, reply, s.intPolicy)
+}
+
+// SetFaultFunc set the func map errors returned by methods to faults, it falls back to the
+// default mapping when the func return nil
+func (s *Server) SetFaultFunc(f func(error) *Fault) {
+	s.faultFunc = f
+}
+
+// SetIntPolicy set how go int and uint in replies are written, default IntStrict
+func (s *Server) SetIntPolicy(p intPolicy) {
+	s.intPolicy = p
+}
+
+// fault map an error returned by method to fault
+func (s *Server) fault(err error) *Fault {
+	if s.faultFunc != nil {
+		if f := s.faultFunc(err); f != nil {
+			return f
+		}
+	}
+	return faultOf(err)
+}
+
+// call invoke method, a panic is recovered and returned as fault
+func (s *Server) call(m string, method reflect.Value, in []reflect.Value) (out []reflect.Value, f *Fault) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("hessian: panic in method %s: %v\n%s", m, r, debug.Stack())
+			f = &Fault{Code: "ServiceException", Message: fmt.Sprintf("panic: %v", r)}
+		}
+	}()
+	return method.Call(in), nil
+}
+
+// ReadRequest read the call in body of hessian request r by its Content-Encoding, return method name
+// and arguments, malformed input is returned as error
+func (i *DeserializerV1) ReadRequest(r *http.Request) (m string, args []interface{}, err error) {
+	body, err := decompress(r.Header.Get("Content-Encoding"), r.Body)
+	if err != nil {
+		return "", nil, err
+	}
+	defer body.Close()
+
+	p, err := ioutil.ReadAll(body)
+	if err != nil {
+		return "", nil, err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("parse call: malformed input")
+		}
+	}()
+	m, args, _, err = i.ReadCallAt(p, 0)
+	return m, args, err
+}
+
+// serverArgs convert arguments to parameter types of method type t
+func serverArgs(ctx context.Context, t reflect.Type, args []interface{}) ([]reflect.Value, error) {
+	var in []reflect.Value
+	offset := 0
+	if t.NumIn() > 0 && t.In(0) == contextType {
+		in = append(in, reflect.ValueOf(ctx))
+		offset = 1
+	}
+
+	if len(args) != t.NumIn()-offset {
+		return nil, fmt.Errorf("expect %d arguments, got %d", t.NumIn()-offset, len(args))
+	}
+
+	for i, arg := range args {
+		pt := t.In(i + offset)
+		if arg == nil {
+			in = append(in, reflect.Zero(pt))
+			continue
+		}
+		v, err := convertValue(arg, pt)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %v", i+1, err)
+		}
+		in = append(in, v)
+	}
+	return in, nil
+}
+
+// writeServerReply write reply of v, go int and uint are written by policy p
+func writeServerReply(w http.ResponseWriter, v interface{}, p intPolicy) {
+	o := NewSerializerV1()
+	o.SetIntPolicy(p)
+	if err := o.WriteReply(v, nil); err != nil {
+		writeServerFault(w, &Fault{Code: "ServiceException", Message: err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "x-application/hessian")
+	w.Write(o.buf.Bytes())
+}
+
+// writeServerFault write fault f, the detail is dropped if it can not be written
+func writeServerFault(w http.ResponseWriter, f *Fault) {
+	o := NewSerializerV1()
+	if err := o.WriteFault(f.Code, f.Message, f.Detail); err != nil {
+		o.Flush()
+		o.WriteFault(f.Code, f.Message, nil)
+	}
+
+	w.Header().Set("Content-Type", "x-application/hessian")
+	w.Write(o.buf.Bytes())
+}
@@ -0,0 +1,282 @@
+package hessian
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type serverUser struct {
+	Package `hessian:"lab.ggw.User"`
+	Name    string `hessian:"name"`
+	Age     int32  `hessian:"age"`
+}
+
+type serverService struct{}
+
+func (serverService) GetUser(ctx context.Context, name string) (*serverUser, error) {
+	if ctx == nil {
+		return nil, errors.New("no context")
+	}
+	return &serverUser{Name: name, Age: 18}, nil
+}
+
+func (serverService) Add(a, b int64) int64 {
+	return a + b
+}
+
+func (serverService) Save(u serverUser) error {
+	if len(u.Name) == 0 {
+		return errors.New("name is empty")
+	}
+	return nil
+}
+
+func (serverService) Names(users []*serverUser) []string {
+	var ans []string
+	for _, u := range users {
+		ans = append(ans, u.Name)
+	}
+	return ans
+}
+
+func (serverService) Nothing() {}
+
+func (serverService) Panic() {
+	panic("oops")
+}
+
+func (serverService) Find(name string) (*serverUser, error) {
+	return nil, &notFoundError{Message: "no user " + name}
+}
+
+func TestNewServer(t *testing.T) {
+	tests := []struct {
+		name    string
+		service interface{}
+		wantErr bool
+	}{
+		{name: "ok", service: serverService{}, wantErr: false},
+		{name: "nil", service: nil, wantErr: true},
+		{name: "no methods", service: struct{}{}, wantErr: true},
+		{name: "variadic", service: &variadicService{}, wantErr: true},
+		{name: "results", service: &resultsService{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewServer(tt.service); (err != nil) != tt.wantErr {
+				t.Errorf("NewServer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type variadicService struct{}
+
+func (*variadicService) Sum(a ...int) int { return 0 }
+
+type resultsService struct{}
+
+func (*resultsService) Pair() (int, int) { return 0, 0 }
+
+func TestServer_ServeHTTP(t *testing.T) {
+	s, err := NewServer(serverService{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		conf     ProxyConfig
+		m        string
+		args     []interface{}
+		want     []interface{}
+		wantCode string
+	}{
+		{
+			name: "struct reply",
+			m:    "getUser",
+			args: []interface{}{"ggwhite"},
+			want: []interface{}{map[interface{}]interface{}{"name": "ggwhite", "age": int32(18)}},
+		},
+		{
+			name: "convert arguments",
+			m:    "add",
+			args: []interface{}{1, int64(2)},
+			want: []interface{}{int64(3)},
+		},
+		{
+			name: "struct argument",
+			m:    "save",
+			args: []interface{}{&serverUser{Name: "ggwhite"}},
+			want: []interface{}{nil},
+		},
+		{
+			name: "list argument",
+			m:    "names",
+			args: []interface{}{[]*serverUser{{Name: "a"}, {Name: "b"}}},
+			want: []interface{}{[]interface{}{"a", "b"}},
+		},
+		{
+			name: "no result",
+			m:    "Nothing",
+			want: []interface{}{nil},
+		},
+		{
+			name: "arity mangling",
+			conf: ProxyConfig{Mangle: MangleArity},
+			m:    "add",
+			args: []interface{}{int64(1), int64(2)},
+			want: []interface{}{int64(3)},
+		},
+		{
+			name: "type mangling",
+			conf: ProxyConfig{Mangle: MangleType},
+			m:    "add",
+			args: []interface{}{int64(1), int64(2)},
+			want: []interface{}{int64(3)},
+		},
+		{
+			name:     "service error",
+			m:        "save",
+			args:     []interface{}{&serverUser{}},
+			wantCode: "ServiceException",
+		},
+		{
+			name:     "panic",
+			m:        "panic",
+			wantCode: "ServiceException",
+		},
+		{
+			name:     "no such method",
+			m:        "delete",
+			wantCode: "NoSuchMethodException",
+		},
+		{
+			name:     "wrong argument count",
+			m:        "add",
+			args:     []interface{}{int64(1)},
+			wantCode: "ProtocolException",
+		},
+		{
+			name:     "wrong argument type",
+			m:        "add",
+			args:     []interface{}{"a", "b"},
+			wantCode: "ProtocolException",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := tt.conf
+			conf.Version = V1
+			conf.URL = srv.URL
+			proxy, err := NewProxy(&conf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := proxy.Invoke(tt.m, tt.args...)
+			if len(tt.wantCode) > 0 {
+				if f, ok := err.(*Fault); !ok || f.Code != tt.wantCode {
+					t.Errorf("Proxy.Invoke() error = %v, want fault %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Proxy.Invoke() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Proxy.Invoke() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_ServeHTTP_Invalid(t *testing.T) {
+	s, err := NewServer(serverService{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "GET", method: http.MethodGet, wantStatus: http.StatusMethodNotAllowed},
+		{name: "not a call", method: http.MethodPost, body: "r\x01\x00Nz", wantStatus: http.StatusOK, wantCode: "ProtocolException"},
+		{name: "truncated", method: http.MethodPost, body: "c\x01\x00m\x00\x09getU", wantStatus: http.StatusOK, wantCode: "ProtocolException"},
+		{name: "bad header", method: http.MethodPost, body: "c\x01\x00H\x00\x09a", wantStatus: http.StatusOK, wantCode: "ProtocolException"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body)))
+			if w.Code != tt.wantStatus {
+				t.Errorf("Server.ServeHTTP() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if len(tt.wantCode) == 0 {
+				return
+			}
+			_, _, err := NewDeserializerV1().ReadAt(w.Body.Bytes(), 0)
+			if f, ok := err.(*Fault); !ok || f.Code != tt.wantCode {
+				t.Errorf("Server.ServeHTTP() fault = %v, want %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestServer_SetFaultFunc(t *testing.T) {
+	s, err := NewServer(serverService{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	proxy, err := NewProxy(&ProxyConfig{Version: V1, URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// java exception in detail
+	_, err = proxy.Invoke("find", "ggwhite")
+	f, ok := err.(*Fault)
+	if !ok {
+		t.Fatalf("Proxy.Invoke() error = %v, want *Fault", err)
+	}
+	want := &Fault{
+		Code:    "ServiceException",
+		Message: "no user ggwhite",
+		Detail:  map[interface{}]interface{}{"detailMessage": "no user ggwhite"},
+	}
+	if !reflect.DeepEqual(f, want) {
+		t.Errorf("Proxy.Invoke() error = %#v, want %#v", f, want)
+	}
+
+	s.SetFaultFunc(func(err error) *Fault {
+		if _, ok := err.(*notFoundError); ok {
+			return &Fault{Code: "NoSuchObjectException", Message: err.Error()}
+		}
+		return nil
+	})
+
+	_, err = proxy.Invoke("find", "ggwhite")
+	if f, ok := err.(*Fault); !ok || f.Code != "NoSuchObjectException" {
+		t.Errorf("Proxy.Invoke() error = %v, want NoSuchObjectException", err)
+	}
+
+	// fall back to the default mapping
+	_, err = proxy.Invoke("save", &serverUser{})
+	if f, ok := err.(*Fault); !ok || f.Code != "ServiceException" {
+		t.Errorf("Proxy.Invoke() error = %v, want ServiceException", err)
+	}
+}
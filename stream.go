@@ -0,0 +1,163 @@
+package hessian
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Encoder write consecutive hessian values to an output stream
+type Encoder struct {
+	w     io.Writer
+	codec *Codec
+}
+
+// NewEncoder create a hessian 1.0 encoder writing to w
+func NewEncoder(w io.Writer) *Encoder {
+	return defaultCodec.NewEncoder(w)
+}
+
+// NewEncoder create an encoder of the codec writing to w
+func (c *Codec) NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, codec: c}
+}
+
+// Encode write the hessian encoding of v to the stream
+func (e *Encoder) Encode(v interface{}) error {
+	p, err := e.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(p)
+	return err
+}
+
+// Decoder read consecutive hessian values from an input stream, it reads only the bytes of
+// each value, but may buffer data beyond it from r.
+type Decoder struct {
+	r     *bufio.Reader
+	codec *Codec
+	// stack open maps and lists of Token, 'M' or 'V'
+	stack []byte
+}
+
+// NewDecoder create a hessian 1.0 decoder reading from r
+func NewDecoder(r io.Reader) *Decoder {
+	return defaultCodec.NewDecoder(r)
+}
+
+// NewDecoder create a decoder of the codec reading from r
+func (c *Codec) NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r), codec: c}
+}
+
+// Decode read the next value from the stream and store it in v like Unmarshal, it returns io.EOF
+// at the end of the stream and io.ErrUnexpectedEOF if the stream ends within a value.
+func (d *Decoder) Decode(v interface{}) error {
+	var buf bytes.Buffer
+	if err := d.scan(&buf); err != nil {
+		if err == io.EOF && buf.Len() > 0 {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return d.codec.Unmarshal(buf.Bytes(), v)
+}
+
+// scan copy the bytes of the next value to buf
+func (d *Decoder) scan(buf *bytes.Buffer) error {
+	tag, err := d.r.ReadByte()
+	if err != nil {
+		return err
+	}
+	buf.WriteByte(tag)
+
+	switch tag {
+	default:
+		return fmt.Errorf("decode: unknown tag 0x%02x", tag)
+	case 'T', 'F', 'N':
+		return nil
+	case 'I':
+		// I b32 b24 b16 b8
+		return d.copy(buf, 4)
+	case 'L', 'D', 'd':
+		// L b64 b56 b48 b40 b32 b24 b16 b8
+		return d.copy(buf, 8)
+	case 'S', 'B':
+		// S b16 b8 string-value
+		return d.copyString(buf)
+	case 'M':
+		// M t b16 b8 type-string (<key> <value>)* z
+		if err := d.expect(buf, 't'); err != nil {
+			return err
+		}
+		if err := d.copyString(buf); err != nil {
+			return err
+		}
+		return d.scanUntilEnd(buf)
+	case 'V':
+		// V t b16 b8 type-string l b32 b24 b16 b8 <value>* z
+		if err := d.expect(buf, 't'); err != nil {
+			return err
+		}
+		if err := d.copyString(buf); err != nil {
+			return err
+		}
+		if err := d.expect(buf, 'l'); err != nil {
+			return err
+		}
+		if err := d.copy(buf, 4); err != nil {
+			return err
+		}
+		return d.scanUntilEnd(buf)
+	}
+}
+
+// scanUntilEnd copy values to buf until 'z'
+func (d *Decoder) scanUntilEnd(buf *bytes.Buffer) error {
+	for {
+		b, err := d.r.Peek(1)
+		if err != nil {
+			return err
+		}
+		if b[0] == 'z' {
+			d.r.ReadByte()
+			buf.WriteByte('z')
+			return nil
+		}
+		if err := d.scan(buf); err != nil {
+			return err
+		}
+	}
+}
+
+// expect copy the next byte to buf, which must be c
+func (d *Decoder) expect(buf *bytes.Buffer, c byte) error {
+	b, err := d.r.ReadByte()
+	if err != nil {
+		return err
+	}
+	buf.WriteByte(b)
+	if b != c {
+		return fmt.Errorf("decode: expect '%c', got 0x%02x", c, b)
+	}
+	return nil
+}
+
+// copy copy the next n bytes to buf
+func (d *Decoder) copy(buf *bytes.Buffer, n int) error {
+	if _, err := io.CopyN(buf, d.r, int64(n)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// copyString copy b16 b8 and the following bytes to buf
+func (d *Decoder) copyString(buf *bytes.Buffer) error {
+	start := buf.Len()
+	if err := d.copy(buf, 2); err != nil {
+		return err
+	}
+	return d.copy(buf, stringLen(buf.Bytes()[start:]))
+}
@@ -0,0 +1,107 @@
+package hessian
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestEncoder_Decoder(t *testing.T) {
+	values := []interface{}{
+		int32(1),
+		"abc",
+		nil,
+		true,
+		[]interface{}{int64(1), "a"},
+		map[interface{}]interface{}{"a": []interface{}{int32(1)}},
+		&marshalUser{Name: "a", Age: 1},
+		[]byte("bytes"),
+	}
+
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	for _, v := range values {
+		if err := e.Encode(v); err != nil {
+			t.Fatalf("Encoder.Encode() error = %v", err)
+		}
+	}
+
+	want := []interface{}{
+		int32(1),
+		"abc",
+		nil,
+		true,
+		[]interface{}{int64(1), "a"},
+		map[interface{}]interface{}{"a": []interface{}{int32(1)}},
+		map[interface{}]interface{}{"name": "a", "age": int32(1)},
+		[]byte("bytes"),
+	}
+
+	d := NewDecoder(&buf)
+	for i := range want {
+		var got interface{}
+		if err := d.Decode(&got); err != nil {
+			t.Fatalf("Decoder.Decode() %d error = %v", i, err)
+		}
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("Decoder.Decode() %d = %#v, want %#v", i, got, want[i])
+		}
+	}
+
+	var v interface{}
+	if err := d.Decode(&v); err != io.EOF {
+		t.Errorf("Decoder.Decode() error = %v, want io.EOF", err)
+	}
+}
+
+func TestCodec_NewDecoder(t *testing.T) {
+	c, err := NewCodec(&CodecConfig{Version: V1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.RegisterType(reflect.TypeOf(marshalUser{}))
+
+	var buf bytes.Buffer
+	e := c.NewEncoder(&buf)
+	e.Encode(&marshalUser{Name: "a"})
+	e.Encode(&marshalUser{Name: "b"})
+
+	d := c.NewDecoder(&buf)
+	for _, name := range []string{"a", "b"} {
+		var v interface{}
+		if err := d.Decode(&v); err != nil {
+			t.Fatal(err)
+		}
+		if want := (marshalUser{Name: name}); !reflect.DeepEqual(v, want) {
+			t.Errorf("Decoder.Decode() = %#v, want %#v", v, want)
+		}
+	}
+}
+
+func TestDecoder_Decode_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       []byte
+		wantErr error
+	}{
+		{name: "truncated int", p: []byte{'I', 0, 0}, wantErr: io.ErrUnexpectedEOF},
+		{name: "truncated string", p: []byte{'S', 0, 3, 'a'}, wantErr: io.ErrUnexpectedEOF},
+		{name: "unterminated map", p: []byte{'M', 't', 0, 0, 'S', 0, 1, 'a', 'N'}, wantErr: io.ErrUnexpectedEOF},
+		{name: "unknown tag", p: []byte{'?'}},
+		{name: "map without t", p: []byte{'M', 'z'}},
+		{name: "list without length", p: []byte{'V', 't', 0, 0, 'z'}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v interface{}
+			err := NewDecoder(bytes.NewReader(tt.p)).Decode(&v)
+			if err == nil {
+				t.Fatalf("Decoder.Decode() error = nil, want error")
+			}
+			if tt.wantErr != nil && err != tt.wantErr {
+				t.Errorf("Decoder.Decode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
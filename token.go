@@ -0,0 +1,204 @@
+package hessian
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// Token a hessian event returned by Decoder.Token, one of:
+//
+//	StartMap, EndMap, StartList, EndList, Ref
+//	nil, bool, int32, int64, float64, time.Time, string (also xml), []byte
+type Token interface{}
+
+// StartMap start of a map, Type is the class name of an object or empty for an untyped map.
+// It is followed by key and value tokens until EndMap.
+type StartMap struct {
+	Type string
+}
+
+// EndMap end of a map
+type EndMap struct{}
+
+// StartList start of a list, Type is the list type and Len is -1 when they are absent.
+// It is followed by item tokens until EndList.
+type StartList struct {
+	Type string
+	Len  int
+}
+
+// EndList end of a list
+type EndList struct{}
+
+// Ref reference to the n-th map or list in the stream, counted from 0
+type Ref int32
+
+// Token return the next token of the stream, it returns io.EOF at the end of the stream and
+// io.ErrUnexpectedEOF if the stream ends within a map or list.
+//
+// Chunked strings and binaries are returned as one token. Token and Decode can be mixed, Decode
+// read the next whole value, ex: the value of a map entry after its key token.
+func (d *Decoder) Token() (Token, error) {
+	tok, err := d.token()
+	if err == io.EOF && len(d.stack) > 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return tok, err
+}
+
+// More report whether there is another item or entry before the end of the current map or list
+func (d *Decoder) More() bool {
+	b, err := d.r.Peek(1)
+	return err == nil && b[0] != 'z'
+}
+
+// token read the next token, io.EOF is returned only at the start of a token
+func (d *Decoder) token() (Token, error) {
+	tag, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tag {
+	default:
+		return nil, fmt.Errorf("token: unknown tag 0x%02x", tag)
+	case 'N':
+		return nil, nil
+	case 'T':
+		return true, nil
+	case 'F':
+		return false, nil
+	case 'I':
+		// I b32 b24 b16 b8
+		v, err := d.uint(4)
+		return int32(v), err
+	case 'L':
+		// L b64 b56 b48 b40 b32 b24 b16 b8
+		v, err := d.uint(8)
+		return int64(v), err
+	case 'D':
+		// D b64 b56 b48 b40 b32 b24 b16 b8
+		v, err := d.uint(8)
+		return math.Float64frombits(v), err
+	case 'd':
+		// d b64 b56 b48 b40 b32 b24 b16 b8
+		v, err := d.uint(8)
+		ms := int64(v)
+		return time.Unix(ms/1e3, ms%1e3*1e6), err
+	case 'R':
+		// R b32 b24 b16 b8
+		v, err := d.uint(4)
+		return Ref(int32(v)), err
+	case 'S', 's', 'X', 'x':
+		b, err := d.chunks(tag)
+		return string(b), err
+	case 'B', 'b':
+		return d.chunks(tag)
+	case 'M':
+		// M t b16 b8 type-name (key value)* z
+		typ, err := d.typeName()
+		if err != nil {
+			return nil, err
+		}
+		d.stack = append(d.stack, 'M')
+		return StartMap{Type: typ}, nil
+	case 'V':
+		// V t b16 b8 type-name l b32 b24 b16 b8 value* z
+		typ, err := d.typeName()
+		if err != nil {
+			return nil, err
+		}
+		l := -1
+		if b, err := d.r.Peek(1); err == nil && b[0] == 'l' {
+			d.r.ReadByte()
+			v, err := d.uint(4)
+			if err != nil {
+				return nil, err
+			}
+			l = int(int32(v))
+		}
+		d.stack = append(d.stack, 'V')
+		return StartList{Type: typ, Len: l}, nil
+	case 'z':
+		if len(d.stack) == 0 {
+			return nil, fmt.Errorf("token: unexpected 'z'")
+		}
+		top := d.stack[len(d.stack)-1]
+		d.stack = d.stack[:len(d.stack)-1]
+		if top == 'M' {
+			return EndMap{}, nil
+		}
+		return EndList{}, nil
+	}
+}
+
+// uint read n bytes as big endian unsigned integer
+func (d *Decoder) uint(n int) (uint64, error) {
+	var v uint64
+	for k := 0; k < n; k++ {
+		b, err := d.r.ReadByte()
+		if err != nil {
+			return 0, unexpectedEOF(err)
+		}
+		v = v<<8 | uint64(b)
+	}
+	return v, nil
+}
+
+// string read b16 b8 data, length is in bytes as stringLen
+func (d *Decoder) string() ([]byte, error) {
+	var l [2]byte
+	if _, err := io.ReadFull(d.r, l[:]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	p := make([]byte, stringLen(l[:]))
+	if _, err := io.ReadFull(d.r, p); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return p, nil
+}
+
+// chunks read b16 b8 data after tag, and the following chunks until the final one
+func (d *Decoder) chunks(tag byte) ([]byte, error) {
+	var ans []byte
+	for {
+		p, err := d.string()
+		if err != nil {
+			return nil, err
+		}
+		ans = append(ans, p...)
+
+		// a chunk is tagged in lower case, the final one in upper case
+		if tag < 'a' {
+			return ans, nil
+		}
+		next, err := d.r.ReadByte()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if next != tag && next != tag-'a'+'A' {
+			return nil, fmt.Errorf("token: chunk '%c' followed by 0x%02x", tag, next)
+		}
+		tag = next
+	}
+}
+
+// typeName read optional t b16 b8 type-name
+func (d *Decoder) typeName() (string, error) {
+	if b, err := d.r.Peek(1); err != nil || b[0] != 't' {
+		return "", nil
+	}
+	d.r.ReadByte()
+	p, err := d.string()
+	return string(p), err
+}
+
+// unexpectedEOF convert io.EOF within a token to io.ErrUnexpectedEOF
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
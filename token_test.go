@@ -0,0 +1,189 @@
+package hessian
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDecoder_Token(t *testing.T) {
+	date := time.Date(2019, 4, 18, 16, 20, 52, 0, time.UTC)
+	ms := date.UnixNano() / 1e6
+
+	tests := []struct {
+		name    string
+		p       []byte
+		want    []Token
+		wantErr error
+	}{
+		{
+			name: "scalars",
+			p: []byte{
+				'N', 'T', 'F',
+				'I', 0, 0, 0, 1,
+				'L', 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+				'D', 0x3f, 0xf0, 0, 0, 0, 0, 0, 0,
+				'd', byte(ms >> 56), byte(ms >> 48), byte(ms >> 40), byte(ms >> 32), byte(ms >> 24), byte(ms >> 16), byte(ms >> 8), byte(ms),
+				'R', 0, 0, 0, 2,
+			},
+			want: []Token{nil, true, false, int32(1), int64(-1), float64(1), date.Local(), Ref(2)},
+		},
+		{
+			name: "chunks",
+			p:    []byte{'s', 0, 2, 'a', 'b', 'S', 0, 1, 'c', 'x', 0, 1, '<', 'X', 0, 1, '>', 'b', 0, 1, 1, 'B', 0, 1, 2},
+			want: []Token{"abc", "<>", []byte{1, 2}},
+		},
+		{
+			name: "typed map",
+			p:    []byte{'M', 't', 0, 1, 'U', 'S', 0, 1, 'a', 'I', 0, 0, 0, 1, 'z'},
+			want: []Token{StartMap{Type: "U"}, "a", int32(1), EndMap{}},
+		},
+		{
+			name: "untyped map",
+			p:    []byte{'M', 'z'},
+			want: []Token{StartMap{}, EndMap{}},
+		},
+		{
+			name: "list",
+			p:    []byte{'V', 't', 0, 4, '[', 'i', 'n', 't', 'l', 0, 0, 0, 1, 'I', 0, 0, 0, 1, 'z'},
+			want: []Token{StartList{Type: "[int", Len: 1}, int32(1), EndList{}},
+		},
+		{
+			name: "nested list without type and length",
+			p:    []byte{'V', 'V', 'z', 'M', 'z', 'z'},
+			want: []Token{StartList{Len: -1}, StartList{Len: -1}, EndList{}, StartMap{}, EndMap{}, EndList{}},
+		},
+		{name: "unterminated map", p: []byte{'M', 'N'}, want: []Token{StartMap{}, nil}, wantErr: io.ErrUnexpectedEOF},
+		{name: "truncated int", p: []byte{'I', 0}, wantErr: io.ErrUnexpectedEOF},
+		{name: "truncated string", p: []byte{'S', 0, 2, 'a'}, wantErr: io.ErrUnexpectedEOF},
+		{name: "chunk without final", p: []byte{'s', 0, 1, 'a'}, wantErr: io.ErrUnexpectedEOF},
+		{name: "chunk mismatch", p: []byte{'s', 0, 1, 'a', 'B', 0, 0}},
+		{name: "unexpected end", p: []byte{'z'}},
+		{name: "unknown tag", p: []byte{'?'}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(bytes.NewReader(tt.p))
+			var got []Token
+			var err error
+			for {
+				var tok Token
+				if tok, err = d.Token(); err != nil {
+					break
+				}
+				got = append(got, tok)
+			}
+
+			wantErr := tt.wantErr
+			if wantErr == nil && tt.want != nil {
+				wantErr = io.EOF
+			}
+			if wantErr != nil && err != wantErr || wantErr == nil && err == io.EOF {
+				t.Errorf("Decoder.Token() error = %v, want %v", err, wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decoder.Token() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecoder_Token_Decode(t *testing.T) {
+	// decode each entry of a huge map by itself
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	e.Encode(map[string]*marshalUser{"a": {Name: "a"}})
+	e.Encode("next")
+
+	d := NewDecoder(&buf)
+	if tok, err := d.Token(); err != nil || tok != (StartMap{}) {
+		t.Fatalf("Decoder.Token() = %v, %v, want StartMap", tok, err)
+	}
+	for d.More() {
+		var k string
+		var u marshalUser
+		if err := d.Decode(&k); err != nil {
+			t.Fatal(err)
+		}
+		if err := d.Decode(&u); err != nil {
+			t.Fatal(err)
+		}
+		if k != "a" || u.Name != "a" {
+			t.Errorf("Decoder.Decode() = %v, %v", k, u)
+		}
+	}
+	if tok, err := d.Token(); err != nil || tok != (EndMap{}) {
+		t.Fatalf("Decoder.Token() = %v, %v, want EndMap", tok, err)
+	}
+
+	var s string
+	if err := d.Decode(&s); err != nil || s != "next" {
+		t.Errorf("Decoder.Decode() = %v, %v, want next", s, err)
+	}
+}
+
+// tokenValue build a value from tokens like DeserializerV1 without type map
+func tokenValue(d *Decoder, tok Token) (interface{}, error) {
+	switch tok.(type) {
+	case StartMap:
+		m := make(map[interface{}]interface{})
+		for d.More() {
+			k, err := tokenNext(d)
+			if err != nil {
+				return nil, err
+			}
+			if m[k], err = tokenNext(d); err != nil {
+				return nil, err
+			}
+		}
+		_, err := d.Token()
+		return m, err
+	case StartList:
+		l := []interface{}{}
+		for d.More() {
+			v, err := tokenNext(d)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, v)
+		}
+		_, err := d.Token()
+		return l, err
+	case EndMap, EndList, Ref:
+		return nil, fmt.Errorf("unexpected %T", tok)
+	}
+	return tok, nil
+}
+
+func tokenNext(d *Decoder) (interface{}, error) {
+	tok, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	return tokenValue(d, tok)
+}
+
+func TestDecoder_Token_DeserializerV1(t *testing.T) {
+	o := NewSerializerV1()
+	o.WriteObject(map[string]interface{}{
+		"list": []interface{}{int32(1), "a", nil, []byte{1}},
+		"user": &marshalUser{Name: "a", Age: 1},
+		"date": time.Date(2019, 4, 18, 16, 20, 52, 0, time.Local),
+	})
+	p := o.buf.Bytes()
+
+	want, _, err := NewDeserializerV1().ReadValueAt(p, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := tokenNext(NewDecoder(bytes.NewReader(p)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tokenNext() = %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,34 @@
+package hessian
+
+import "reflect"
+
+// TypedMap a hessian map with java class name (empty for untyped map), entries keep their order.
+//
+// Used to write objects without a go struct, ex: TypedMap{Type: "lab.ggw.User", Entries: ...}
+type TypedMap struct {
+	Type    string
+	Entries []MapEntry
+}
+
+// MapEntry an entry of TypedMap
+type MapEntry struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// Get return value of the key, and whether it exists. Keys are compared by reflect.DeepEqual, list
+// and map keys are supported.
+func (m TypedMap) Get(key interface{}) (interface{}, bool) {
+	for _, e := range m.Entries {
+		if reflect.DeepEqual(e.Key, key) {
+			return e.Value, true
+		}
+	}
+	return nil, false
+}
+
+// TypedList a hessian list with list type, ex: TypedList{Type: "[int", Items: ...}
+type TypedList struct {
+	Type  string
+	Items []interface{}
+}
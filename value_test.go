@@ -0,0 +1,38 @@
+package hessian
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTypedMap_Get(t *testing.T) {
+	m := TypedMap{Entries: []MapEntry{
+		{Key: "a", Value: 1},
+		{Key: []interface{}{"x", int32(1)}, Value: 2},
+		{Key: map[interface{}]interface{}{"k": "v"}, Value: 3},
+		{Key: int32(4), Value: 4},
+	}}
+
+	tests := []struct {
+		name   string
+		key    interface{}
+		want   interface{}
+		wantOk bool
+	}{
+		{name: "string", key: "a", want: 1, wantOk: true},
+		{name: "list", key: []interface{}{"x", int32(1)}, want: 2, wantOk: true},
+		{name: "map", key: map[interface{}]interface{}{"k": "v"}, want: 3, wantOk: true},
+		{name: "int32", key: int32(4), want: 4, wantOk: true},
+		{name: "other type", key: 4, want: nil, wantOk: false},
+		{name: "not found", key: []interface{}{"y"}, want: nil, wantOk: false},
+		{name: "nil", key: nil, want: nil, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := m.Get(tt.key)
+			if !reflect.DeepEqual(got, tt.want) || ok != tt.wantOk {
+				t.Errorf("TypedMap.Get() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}